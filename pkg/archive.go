@@ -0,0 +1,480 @@
+package pkg
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// How the streams of a session are recorded.
+type ArchiveOutputMode string
+
+const (
+	// All the streams are recorded to a single file.
+	ArchiveOutputComposed ArchiveOutputMode = "composed"
+	// Each stream is recorded to its own file, delivered in a ZIP file.
+	ArchiveOutputIndividual ArchiveOutputMode = "individual"
+)
+
+// Which streams of a session are recorded.
+type ArchiveStreamMode string
+
+const (
+	// All the streams of the session are recorded.
+	ArchiveStreamAuto ArchiveStreamMode = "auto"
+	// Only the streams added with AddArchiveStream are recorded.
+	ArchiveStreamManual ArchiveStreamMode = "manual"
+)
+
+// Resolutions of composed archives, landscape and portrait.
+const (
+	Resolution640x480   = "640x480"
+	Resolution1280x720  = "1280x720"
+	Resolution1920x1080 = "1920x1080"
+	Resolution480x640   = "480x640"
+	Resolution720x1280  = "720x1280"
+	Resolution1080x1920 = "1080x1920"
+)
+
+var archiveResolutions = map[string]bool{
+	Resolution640x480:   true,
+	Resolution1280x720:  true,
+	Resolution1920x1080: true,
+	Resolution480x640:   true,
+	Resolution720x1280:  true,
+	Resolution1080x1920: true,
+}
+
+// Bounds of ArchiveOptions.MaxBitrate and ArchiveOptions.QuantizationParameter.
+const (
+	ArchiveMinBitrate      = 100000
+	ArchiveMaxBitrate      = 6000000
+	ArchiveMinQuantization = 15
+	ArchiveMaxQuantization = 40
+)
+
+var (
+	ErrorMissingArchiveId              = errors.New("an archiveId parameter is required")
+	ErrorArchiveNotFound               = errors.New("the archive was not found")
+	ErrorArchiveConflict               = errors.New("the archive operation conflicts with the state of the archive or session")
+	ErrorArchiveNoMedia                = errors.New("invalid archive options, an archive must have audio, video or both")
+	ErrorArchiveListCount              = errors.New("invalid archive list count, must be between 0 and 1000")
+	ErrorArchiveOutputMode             = errors.New("invalid archive output mode, must be composed or individual")
+	ErrorArchiveIndividualLayout       = errors.New("invalid archive options, a layout is not supported by the individual output mode")
+	ErrorArchiveIndividualResolution   = errors.New("invalid archive options, a resolution is not supported by the individual output mode")
+	ErrorArchiveStreamMode             = errors.New("invalid archive stream mode, must be auto or manual")
+	ErrorArchiveNotManual              = errors.New("the archive does not use the manual stream mode")
+	ErrorStreamNotInSession            = errors.New("the stream is not published in the archived session")
+	ErrorArchiveResolution             = errors.New("invalid archive resolution, must be 640x480, 1280x720, 1920x1080, 480x640, 720x1280 or 1080x1920")
+	ErrorArchiveBitrateAndQuantization = errors.New("invalid archive options, maxBitrate and quantizationParameter cannot be combined")
+	ErrorArchiveMaxBitrate             = errors.New("invalid archive maxBitrate, must be between 100000 and 6000000")
+	ErrorArchiveQuantization           = errors.New("invalid archive quantizationParameter, must be between 15 and 40")
+	ErrorArchiveIndividualQuality      = errors.New("invalid archive options, maxBitrate and quantizationParameter are not supported by the individual output mode")
+	ErrorArchiveAudioOnlyQuality       = errors.New("invalid archive options, maxBitrate and quantizationParameter need the video to be recorded")
+	ErrorTranscriptionOutputMode       = errors.New("invalid archive options, transcription is only supported by the individual output mode")
+	ErrorTranscriptionAudio            = errors.New("invalid archive options, transcription needs the audio to be recorded")
+	ErrorTranscriptionProperties       = errors.New("invalid archive options, transcription properties need hasTranscription")
+	ErrorTranscriptionLanguage         = errors.New("invalid transcription language code, must be a language and region code such as en-US")
+)
+
+// An OpenTok archive, the recording of a session.
+type Archive struct {
+	// The archive ID.
+	Id string `json:"id"`
+	// The status of the archive, one of the ArchiveStatus* values.
+	Status ArchiveStatus `json:"status"`
+	// The archive name, if one was set when the archive was started.
+	Name string `json:"name"`
+	// The session ID of the OpenTok session that was recorded.
+	SessionId string `json:"sessionId"`
+	// The API key of the project.
+	ProjectId int64 `json:"projectId"`
+	PartnerId int64 `json:"partnerId"`
+	// Why the archive stopped or failed, e.g. "user initiated".
+	Reason string `json:"reason"`
+	// The size of the archive file in bytes, 0 while recording.
+	Size int64 `json:"size"`
+	// The duration of the archive in seconds, 0 while recording.
+	Duration int64 `json:"duration"`
+	// The download URL of an available archive, empty otherwise.
+	Url string `json:"url"`
+	// When the archive was started, in milliseconds since the UNIX epoch.
+	CreatedAt int64 `json:"createdAt"`
+	// Either ArchiveOutputComposed or ArchiveOutputIndividual.
+	OutputMode ArchiveOutputMode `json:"outputMode"`
+	// Either ArchiveStreamAuto or ArchiveStreamManual.
+	StreamMode ArchiveStreamMode `json:"streamMode"`
+	// The resolution of a composed archive, e.g. "640x480".
+	Resolution string `json:"resolution"`
+	HasAudio   bool   `json:"hasAudio"`
+	HasVideo   bool   `json:"hasVideo"`
+	// The transcription of an archive started with hasTranscription, nil otherwise.
+	Transcription *Transcription `json:"transcription,omitempty"`
+}
+
+// Options of the transcription of an archive.
+type TranscriptionProperties struct {
+	// The language spoken in the archive, e.g. "en-US" (default "en-US").
+	PrimaryLanguageCode string `json:"primaryLanguageCode,omitempty"`
+	// Whether to also generate a summary of the transcript.
+	HasSummary bool `json:"hasSummary,omitempty"`
+}
+
+// The transcription of an archive.
+type Transcription struct {
+	// The transcription status, e.g. "requested", "started", "available" or "failed".
+	Status string `json:"status"`
+	// The download URL of an available transcript, empty otherwise.
+	Url                 string `json:"url"`
+	PrimaryLanguageCode string `json:"primaryLanguageCode"`
+	HasSummary          bool   `json:"hasSummary"`
+	// Why the transcription failed.
+	Reason string `json:"reason"`
+}
+
+// The time the archive was started.
+func (a *Archive) CreatedTime() time.Time {
+	return time.Unix(0, a.CreatedAt*int64(time.Millisecond))
+}
+
+// Options of {@link OpenTok#StartArchive OpenTok.StartArchive()}, all of them optional.
+type ArchiveOptions struct {
+	// The name of the archive, to identify it.
+	Name string `json:"name,omitempty"`
+	// Whether to record all the streams to a single file (default) or each stream to its own
+	// file. Layout and Resolution are only supported by the composed output mode.
+	OutputMode ArchiveOutputMode `json:"outputMode,omitempty"`
+	// Whether to record all the streams of the session (default) or only the streams added
+	// with {@link OpenTok#AddArchiveStream OpenTok.AddArchiveStream()}.
+	StreamMode ArchiveStreamMode `json:"streamMode,omitempty"`
+	// The resolution of a composed archive, one of the Resolution* values (default "640x480").
+	Resolution string `json:"resolution,omitempty"`
+	// Whether to record audio (default true).
+	HasAudio *bool `json:"hasAudio,omitempty"`
+	// Whether to record video (default true), an audio-only archive has HasVideo false.
+	HasVideo *bool `json:"hasVideo,omitempty"`
+	// The maximum video bitrate of a composed archive in bits per second, between 100000 and
+	// 6000000. Cannot be combined with QuantizationParameter.
+	MaxBitrate int `json:"maxBitrate,omitempty"`
+	// The video quality of a composed archive, between 15 (best) and 40. Cannot be combined
+	// with MaxBitrate.
+	QuantizationParameter int `json:"quantizationParameter,omitempty"`
+	// Allows several archives of the same session to record at once, archives started with
+	// the same tag are deduplicated.
+	MultiArchiveTag string `json:"multiArchiveTag,omitempty"`
+	// The initial layout of a composed archive (default bestFit), it can be changed with
+	// {@link OpenTok#SetArchiveLayout OpenTok.SetArchiveLayout()}.
+	Layout *Layout `json:"layout,omitempty"`
+	// Whether to transcribe the audio of the archive, only supported by the individual output
+	// mode.
+	HasTranscription bool `json:"hasTranscription,omitempty"`
+	// The transcription options, with HasTranscription only.
+	TranscriptionProperties *TranscriptionProperties `json:"transcriptionProperties,omitempty"`
+}
+
+// a language and region code such as en-US
+var languageCode = regexp.MustCompile(`^[a-z]{2,3}-[A-Z]{2}$`)
+
+func (o *ArchiveOptions) individual() bool {
+	return o.OutputMode == ArchiveOutputIndividual
+}
+
+func (o *ArchiveOptions) noAudio() bool {
+	return o.HasAudio != nil && !*o.HasAudio
+}
+
+func (o *ArchiveOptions) noVideo() bool {
+	return o.HasVideo != nil && !*o.HasVideo
+}
+
+// the combinations of archive options rejected before the request is sent, checked in order
+var archiveOptionRules = []struct {
+	invalid func(o *ArchiveOptions) bool
+	err     error
+}{
+	{func(o *ArchiveOptions) bool { return o.noAudio() && o.noVideo() }, ErrorArchiveNoMedia},
+	{func(o *ArchiveOptions) bool {
+		return len(o.OutputMode) != 0 && o.OutputMode != ArchiveOutputComposed && o.OutputMode != ArchiveOutputIndividual
+	}, ErrorArchiveOutputMode},
+	{func(o *ArchiveOptions) bool {
+		return len(o.StreamMode) != 0 && o.StreamMode != ArchiveStreamAuto && o.StreamMode != ArchiveStreamManual
+	}, ErrorArchiveStreamMode},
+	{func(o *ArchiveOptions) bool { return len(o.Resolution) != 0 && !archiveResolutions[o.Resolution] }, ErrorArchiveResolution},
+	{func(o *ArchiveOptions) bool { return o.individual() && o.Layout != nil }, ErrorArchiveIndividualLayout},
+	{func(o *ArchiveOptions) bool { return o.individual() && len(o.Resolution) != 0 }, ErrorArchiveIndividualResolution},
+	{func(o *ArchiveOptions) bool { return o.MaxBitrate != 0 && o.QuantizationParameter != 0 }, ErrorArchiveBitrateAndQuantization},
+	{func(o *ArchiveOptions) bool {
+		return o.MaxBitrate != 0 && (o.MaxBitrate < ArchiveMinBitrate || o.MaxBitrate > ArchiveMaxBitrate)
+	}, ErrorArchiveMaxBitrate},
+	{func(o *ArchiveOptions) bool {
+		return o.QuantizationParameter != 0 &&
+			(o.QuantizationParameter < ArchiveMinQuantization || o.QuantizationParameter > ArchiveMaxQuantization)
+	}, ErrorArchiveQuantization},
+	{func(o *ArchiveOptions) bool {
+		return o.individual() && (o.MaxBitrate != 0 || o.QuantizationParameter != 0)
+	}, ErrorArchiveIndividualQuality},
+	{func(o *ArchiveOptions) bool {
+		return o.noVideo() && (o.MaxBitrate != 0 || o.QuantizationParameter != 0)
+	}, ErrorArchiveAudioOnlyQuality},
+	{func(o *ArchiveOptions) bool { return o.HasTranscription && !o.individual() }, ErrorTranscriptionOutputMode},
+	{func(o *ArchiveOptions) bool { return o.HasTranscription && o.noAudio() }, ErrorTranscriptionAudio},
+	{func(o *ArchiveOptions) bool { return !o.HasTranscription && o.TranscriptionProperties != nil }, ErrorTranscriptionProperties},
+	{func(o *ArchiveOptions) bool {
+		return o.TranscriptionProperties != nil && len(o.TranscriptionProperties.PrimaryLanguageCode) != 0 &&
+			!languageCode.MatchString(o.TranscriptionProperties.PrimaryLanguageCode)
+	}, ErrorTranscriptionLanguage},
+}
+
+func (o *ArchiveOptions) validate() error {
+	for _, rule := range archiveOptionRules {
+		if rule.invalid(o) {
+			return rule.err
+		}
+	}
+	if o.Layout != nil {
+		return o.Layout.validate()
+	}
+	return nil
+}
+
+type startArchiveRequest struct {
+	SessionId string `json:"sessionId"`
+	*ArchiveOptions
+}
+
+// Filters of {@link OpenTok#ListArchives OpenTok.ListArchives()}, all of them optional.
+type ArchiveFilter struct {
+	// The index of the first archive to return, archives are listed newest first.
+	Offset int
+	// The number of archives to return, at most 1000 (default 50).
+	Count int
+	// Only list the archives of this session.
+	SessionId string
+}
+
+type ArchiveList struct {
+	// The total number of archives matching the filter.
+	Count int        `json:"count"`
+	Items []*Archive `json:"items"`
+}
+
+func (c *Client) startArchive(sessionId string, options *ArchiveOptions) (*Archive, error) {
+	var archive Archive
+	err := c.request(http.MethodPost, c.endpointUrl(c.config.Endpoints.StartArchive),
+		&startArchiveRequest{sessionId, options}, &archive,
+		map[int]error{
+			http.StatusNotFound: ErrorSessionNotFound,
+			http.StatusConflict: ErrorArchiveConflict,
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+func (c *Client) stopArchive(archiveId string) (*Archive, error) {
+	var archive Archive
+	err := c.request(http.MethodPost, c.endpointUrl(c.config.Endpoints.StopArchive, archiveId), nil, &archive,
+		map[int]error{
+			http.StatusNotFound: ErrorArchiveNotFound,
+			http.StatusConflict: ErrorArchiveConflict,
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+func (c *Client) getArchive(archiveId string) (*Archive, error) {
+	var archive Archive
+	err := c.request(http.MethodGet, c.endpointUrl(c.config.Endpoints.GetArchive, archiveId), nil, &archive,
+		map[int]error{
+			http.StatusNotFound: ErrorArchiveNotFound,
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+func (c *Client) listArchives(filter *ArchiveFilter) (*ArchiveList, error) {
+	query := url.Values{}
+	if filter.Offset > 0 {
+		query.Set("offset", strconv.Itoa(filter.Offset))
+	}
+	if filter.Count > 0 {
+		query.Set("count", strconv.Itoa(filter.Count))
+	}
+	if len(filter.SessionId) != 0 {
+		query.Set("sessionId", filter.SessionId)
+	}
+	listUrl := c.endpointUrl(c.config.Endpoints.ListArchives)
+	if len(query) != 0 {
+		listUrl += "?" + query.Encode()
+	}
+	var archives ArchiveList
+	err := c.request(http.MethodGet, listUrl, nil, &archives, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &archives, nil
+}
+
+func (c *Client) deleteArchive(archiveId string) error {
+	return c.request(http.MethodDelete, c.endpointUrl(c.config.Endpoints.DeleteArchive, archiveId), nil, nil,
+		map[int]error{
+			http.StatusNotFound: ErrorArchiveNotFound,
+			http.StatusConflict: ErrorArchiveConflict,
+		})
+}
+
+type archiveStreamRequest struct {
+	AddStream    string `json:"addStream,omitempty"`
+	RemoveStream string `json:"removeStream,omitempty"`
+	HasAudio     *bool  `json:"hasAudio,omitempty"`
+	HasVideo     *bool  `json:"hasVideo,omitempty"`
+}
+
+func (c *Client) patchArchiveStreams(archiveId string, body *archiveStreamRequest) error {
+	return c.request(http.MethodPatch, c.endpointUrl(c.config.Endpoints.ArchiveStreams, archiveId), body, nil,
+		map[int]error{
+			http.StatusBadRequest:       ErrorStreamNotInSession,
+			http.StatusNotFound:         ErrorArchiveNotFound,
+			http.StatusMethodNotAllowed: ErrorArchiveNotManual,
+		})
+}
+
+// Starts recording an OpenTok session. The session must use the routed media mode.
+//
+// @param sessionId The session ID of the OpenTok session to record.
+// @param options The archive options, see ArchiveOptions.
+//
+// @return The started archive. The error wraps ErrorSessionNotFound when the session does not
+// exist and ErrorArchiveConflict when the session is relayed or is already being recorded.
+func (ot *OpenTok) StartArchive(sessionId string, options ArchiveOptions) (*Archive, error) {
+	if len(sessionId) == 0 {
+		return nil, ErrorMissingSessionId
+	}
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	return ot.client.startArchive(sessionId, &options)
+}
+
+// Stops recording an archive.
+//
+// @param archiveId The archive ID.
+//
+// @return The stopped archive. The error wraps ErrorArchiveNotFound when the archive does not
+// exist and ErrorArchiveConflict when it is not being recorded.
+func (ot *OpenTok) StopArchive(archiveId string) (*Archive, error) {
+	if len(archiveId) == 0 {
+		return nil, ErrorMissingArchiveId
+	}
+	return ot.client.stopArchive(archiveId)
+}
+
+// Gets an archive.
+//
+// @param archiveId The archive ID.
+//
+// @return The archive. The error wraps ErrorArchiveNotFound when the archive does not exist.
+func (ot *OpenTok) GetArchive(archiveId string) (*Archive, error) {
+	if len(archiveId) == 0 {
+		return nil, ErrorMissingArchiveId
+	}
+	return ot.client.getArchive(archiveId)
+}
+
+// Lists the archives of the project, newest first.
+//
+// @param filter The offset, count and session of the archives to list.
+//
+// @return One page of archives and the total number of archives matching the filter.
+func (ot *OpenTok) ListArchives(filter ArchiveFilter) (*ArchiveList, error) {
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	if filter.Count < 0 || filter.Count > 1000 {
+		return nil, ErrorArchiveListCount
+	}
+	return ot.client.listArchives(&filter)
+}
+
+// Deletes an archive. Only archives with the "available", "uploaded" or "expired" status
+// can be deleted.
+//
+// @param archiveId The archive ID.
+//
+// @return The error wraps ErrorArchiveNotFound when the archive does not exist and
+// ErrorArchiveConflict when its status does not allow deletion.
+func (ot *OpenTok) DeleteArchive(archiveId string) error {
+	if len(archiveId) == 0 {
+		return ErrorMissingArchiveId
+	}
+	return ot.client.deleteArchive(archiveId)
+}
+
+// Adds a stream to an archive that uses the manual stream mode.
+//
+// @param archiveId The archive ID.
+// @param streamId The stream ID, the stream must be published in the archived session.
+// @param hasAudio Whether to record the audio of the stream.
+// @param hasVideo Whether to record the video of the stream.
+//
+// @return The error wraps ErrorArchiveNotManual when the archive uses the auto stream mode,
+// ErrorStreamNotInSession when the stream is not published in the session and
+// ErrorArchiveNotFound when the archive does not exist.
+func (ot *OpenTok) AddArchiveStream(archiveId, streamId string, hasAudio, hasVideo bool) error {
+	if len(archiveId) == 0 {
+		return ErrorMissingArchiveId
+	}
+	if len(streamId) == 0 {
+		return ErrorMissingStreamId
+	}
+	if !hasAudio && !hasVideo {
+		return ErrorArchiveNoMedia
+	}
+	return ot.client.patchArchiveStreams(archiveId, &archiveStreamRequest{
+		AddStream: streamId,
+		HasAudio:  &hasAudio,
+		HasVideo:  &hasVideo,
+	})
+}
+
+// Removes a stream from an archive that uses the manual stream mode.
+//
+// @param archiveId The archive ID.
+// @param streamId The stream ID.
+//
+// @return The errors of {@link OpenTok#AddArchiveStream OpenTok.AddArchiveStream()}.
+func (ot *OpenTok) RemoveArchiveStream(archiveId, streamId string) error {
+	if len(archiveId) == 0 {
+		return ErrorMissingArchiveId
+	}
+	if len(streamId) == 0 {
+		return ErrorMissingStreamId
+	}
+	return ot.client.patchArchiveStreams(archiveId, &archiveStreamRequest{RemoveStream: streamId})
+}
+
+// Starts recording this session.
+// See {@link OpenTok#StartArchive OpenTok.StartArchive()}.
+func (s *Session) StartArchive(options ArchiveOptions) (*Archive, error) {
+	if s.ot == nil {
+		return nil, ErrorSessionNotBound
+	}
+	return s.ot.StartArchive(s.sessionId, options)
+}
+
+// Lists the archives of this session, newest first.
+// See {@link OpenTok#ListArchives OpenTok.ListArchives()}.
+func (s *Session) ListArchives(offset, count int) (*ArchiveList, error) {
+	if s.ot == nil {
+		return nil, ErrorSessionNotBound
+	}
+	return s.ot.ListArchives(ArchiveFilter{Offset: offset, Count: count, SessionId: s.sessionId})
+}
@@ -0,0 +1,416 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ErrorArchiveNotAvailable = errors.New("the archive cannot be downloaded, it must be available with a url and a size")
+	ErrorArchiveSize         = errors.New("the downloaded archive does not match the archive size")
+)
+
+type DownloadOptions struct {
+	// The size of the byte ranges downloaded in parallel (default 16MB).
+	ChunkSize int64
+	// How many chunks are downloaded at once (default 4).
+	Concurrency int
+	// How many times a chunk is retried when its download fails without progress, a download
+	// that stopped halfway resumes where it stopped (default 3, negative to never retry).
+	Retries int
+	// The delay before the first retry of a chunk, doubled after every retry (default 1s).
+	RetryDelay time.Duration
+	// Called with the number of bytes downloaded so far and the archive size, every time a
+	// chunk progresses. Calls are serialized. Optional.
+	OnProgress func(downloaded, size int64)
+	// The client used for the download requests (default http.DefaultClient).
+	HttpClient *http.Client
+}
+
+// a write failure of the destination, never retried
+type downloadWriteError struct {
+	err error
+}
+
+func (e *downloadWriteError) Error() string {
+	return fmt.Sprintf("writing the archive failed: %v", e.err)
+}
+
+func (e *downloadWriteError) Unwrap() error {
+	return e.err
+}
+
+type archiveDownload struct {
+	url        string
+	size       int64
+	options    DownloadOptions
+	writer     io.WriterAt
+	mu         sync.Mutex
+	downloaded int64
+}
+
+func newArchiveDownload(archive *Archive, writer io.WriterAt, options DownloadOptions) (*archiveDownload, error) {
+	if archive == nil || len(archive.Url) == 0 || archive.Size <= 0 {
+		return nil, ErrorArchiveNotAvailable
+	}
+	if options.ChunkSize <= 0 {
+		options.ChunkSize = 16 << 20
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 4
+	}
+	if options.Retries < 0 {
+		options.Retries = 0
+	} else if options.Retries == 0 {
+		options.Retries = 3
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = time.Second
+	}
+	if options.HttpClient == nil {
+		options.HttpClient = http.DefaultClient
+	}
+	return &archiveDownload{url: archive.Url, size: archive.Size, options: options, writer: writer}, nil
+}
+
+func (d *archiveDownload) chunks() int {
+	return int((d.size + d.options.ChunkSize - 1) / d.options.ChunkSize)
+}
+
+func (d *archiveDownload) chunkRange(index int) (start, end int64) {
+	start = int64(index) * d.options.ChunkSize
+	end = start + d.options.ChunkSize
+	if end > d.size {
+		end = d.size
+	}
+	return start, end
+}
+
+func (d *archiveDownload) progress(n int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.downloaded += n
+	if d.options.OnProgress != nil {
+		d.options.OnProgress(d.downloaded, d.size)
+	}
+}
+
+// downloads the chunks that are not done, onChunk is called once a chunk is complete
+func (d *archiveDownload) run(ctx context.Context, done map[int]bool, onChunk func(index int) error) error {
+	var skipped int64
+	for index := range done {
+		start, end := d.chunkRange(index)
+		skipped += end - start
+	}
+	d.progress(skipped)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+	indexes := make(chan int)
+	for i := 0; i < d.options.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				err := d.fetchChunk(ctx, index)
+				if err == nil && onChunk != nil {
+					err = onChunk(index)
+				}
+				if err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+feed:
+	for index := 0; index < d.chunks(); index++ {
+		if done[index] {
+			continue
+		}
+		select {
+		case indexes <- index:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d.downloaded != d.size {
+		return ErrorArchiveSize
+	}
+	return nil
+}
+
+// downloads one chunk, resuming after the bytes already written when an attempt fails
+func (d *archiveDownload) fetchChunk(ctx context.Context, index int) error {
+	start, end := d.chunkRange(index)
+	delay := d.options.RetryDelay
+	failures := 0
+	for {
+		n, err := d.fetchRange(ctx, start, end)
+		start += n
+		if err == nil {
+			return nil
+		}
+		if !d.retryable(ctx, err) {
+			return err
+		}
+		if n > 0 {
+			failures = 0
+			delay = d.options.RetryDelay
+		}
+		if failures++; failures > d.options.Retries {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+// network errors, truncated responses and server errors are retried
+func (d *archiveDownload) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrorArchiveSize) {
+		return false
+	}
+	var writeError *downloadWriteError
+	if errors.As(err, &writeError) {
+		return false
+	}
+	var requestError *RequestError
+	if errors.As(err, &requestError) {
+		return errors.Is(err, ErrorServer)
+	}
+	return true
+}
+
+// downloads the bytes [start, end) and returns how many were written
+func (d *archiveDownload) fetchRange(ctx context.Context, start, end int64) (int64, error) {
+	request, err := http.NewRequest(http.MethodGet, d.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+	response, err := d.options.HttpClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		var first, last, total int64
+		if _, err := fmt.Sscanf(response.Header.Get("Content-Range"), "bytes %d-%d/%d", &first, &last, &total); err == nil &&
+			(first != start || total != d.size) {
+			return 0, ErrorArchiveSize
+		}
+	case http.StatusOK:
+		// the server ignored the range, skip to the start of the chunk
+		if response.ContentLength >= 0 && response.ContentLength != d.size {
+			return 0, ErrorArchiveSize
+		}
+		if _, err := io.CopyN(ioutil.Discard, response.Body, start); err != nil {
+			return 0, err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		return 0, ErrorArchiveSize
+	default:
+		requestError := &RequestError{StatusCode: response.StatusCode}
+		switch {
+		case response.StatusCode == http.StatusForbidden:
+			// the download url expired
+			requestError.Err = ErrorAuthentication
+		case response.StatusCode >= 500 && response.StatusCode <= 599:
+			requestError.Err = ErrorServer
+		case response.StatusCode >= 400 && response.StatusCode <= 499:
+			requestError.Err = ErrorInvalidRequest
+		default:
+			requestError.Err = ErrorUnexpected
+		}
+		return 0, requestError
+	}
+
+	buffer := make([]byte, 32<<10)
+	body := io.LimitReader(response.Body, end-start)
+	var written int64
+	for {
+		n, err := body.Read(buffer)
+		if n > 0 {
+			if _, err := d.writer.WriteAt(buffer[:n], start+written); err != nil {
+				return written, &downloadWriteError{err: err}
+			}
+			written += int64(n)
+			d.progress(int64(n))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+	if start+written != end {
+		return written, io.ErrUnexpectedEOF
+	}
+	return written, nil
+}
+
+// Downloads an available archive from its Url. The archive is fetched in chunks downloaded in
+// parallel with HTTP range requests, a chunk interrupted by a network error resumes where it
+// stopped.
+//
+// @param ctx Bounds the download.
+// @param archive The archive, as returned by {@link OpenTok#GetArchive OpenTok.GetArchive()}
+// or {@link OpenTok#WaitForArchive OpenTok.WaitForArchive()}.
+// @param writer Receives the bytes of the archive at their offset, e.g. an *os.File.
+// @param options The chunking, retries and progress callback.
+//
+// @return ErrorArchiveNotAvailable when the archive has no Url or Size, ErrorArchiveSize when
+// the downloaded bytes do not match Archive.Size. An expired download url returns an error
+// wrapping ErrorAuthentication, get the archive again for a new one.
+func DownloadArchive(ctx context.Context, archive *Archive, writer io.WriterAt, options DownloadOptions) error {
+	d, err := newArchiveDownload(archive, writer, options)
+	if err != nil {
+		return err
+	}
+	return d.run(ctx, nil, nil)
+}
+
+// the chunks of a file download already written, saved next to the partial file
+type downloadState struct {
+	ArchiveId string `json:"archiveId"`
+	Size      int64  `json:"size"`
+	ChunkSize int64  `json:"chunkSize"`
+	Done      []int  `json:"done"`
+}
+
+func loadDownloadState(path string) *downloadState {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var state downloadState
+	if json.Unmarshal(data, &state) != nil {
+		return nil
+	}
+	return &state
+}
+
+// whether the state describes the download and the partial file still holds the chunks it
+// records as done
+func (s *downloadState) resumable(archiveId string, d *archiveDownload, partSize int64) bool {
+	if s == nil || s.ArchiveId != archiveId || s.Size != d.size || s.ChunkSize != d.options.ChunkSize {
+		return false
+	}
+	for _, index := range s.Done {
+		if index < 0 || index >= d.chunks() {
+			return false
+		}
+		if _, end := d.chunkRange(index); end > partSize {
+			return false
+		}
+	}
+	return true
+}
+
+// Downloads an available archive to a file, see
+// {@link DownloadArchive DownloadArchive()}. The archive is written to path + ".part" and the
+// completed chunks are recorded in path + ".part.json", so a download that failed or whose
+// process stopped resumes where it stopped when called again with the same archive. The
+// partial file is renamed to path once complete.
+//
+// @param path The file to create, replaced if it exists.
+func DownloadArchiveFile(ctx context.Context, archive *Archive, path string, options DownloadOptions) error {
+	partPath := path + ".part"
+	statePath := partPath + ".json"
+
+	// the size of the partial file before it is opened, -1 when there is none
+	partSize := int64(-1)
+	if info, err := os.Stat(partPath); err == nil {
+		partSize = info.Size()
+	}
+	file, err := os.OpenFile(partPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	d, err := newArchiveDownload(archive, file, options)
+	if err != nil {
+		return err
+	}
+
+	state := loadDownloadState(statePath)
+	if !state.resumable(archive.Id, d, partSize) {
+		// nothing to resume, or the partial file belongs to another download or was removed
+		state = &downloadState{ArchiveId: archive.Id, Size: d.size, ChunkSize: d.options.ChunkSize}
+		if err := file.Truncate(0); err != nil {
+			return err
+		}
+	}
+	done := make(map[int]bool, len(state.Done))
+	for _, index := range state.Done {
+		done[index] = true
+	}
+
+	var mu sync.Mutex
+	err = d.run(ctx, done, func(index int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		state.Done = append(state.Done, index)
+		data, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(statePath, data, 0644)
+	})
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() != d.size {
+		return ErrorArchiveSize
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(partPath, path); err != nil {
+		return err
+	}
+	return os.Remove(statePath)
+}
@@ -0,0 +1,207 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryWriterAt struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (m *memoryWriterAt) WriteAt(p []byte, offset int64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if end := int(offset) + len(p); end > len(m.data) {
+		m.data = append(m.data, make([]byte, end-len(m.data))...)
+	}
+	return copy(m.data[offset:], p), nil
+}
+
+// serves content with range support, truncating the first response of every range when flaky
+// and failing the ranges in forbidden
+type archiveServer struct {
+	content   []byte
+	flaky     bool
+	forbidden map[string]bool
+	mu        sync.Mutex
+	ranges    []string
+}
+
+func (s *archiveServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	rangeHeader := r.Header.Get("Range")
+	seen := false
+	for _, previous := range s.ranges {
+		seen = seen || previous == rangeHeader
+	}
+	s.ranges = append(s.ranges, rangeHeader)
+	s.mu.Unlock()
+
+	if s.forbidden[rangeHeader] {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if s.flaky && !seen {
+		var first, last int
+		_, _ = fmt.Sscanf(rangeHeader, "bytes=%d-%d", &first, &last)
+		w.Header().Set("Content-Length", strconv.Itoa(last-first+1))
+		w.Header().Set("Content-Range", "bytes "+strconv.Itoa(first)+"-"+strconv.Itoa(last)+"/"+strconv.Itoa(len(s.content)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(s.content[first : first+(last-first+1)/2])
+		return
+	}
+	http.ServeContent(w, r, "archive.mp4", time.Time{}, bytes.NewReader(s.content))
+}
+
+func (s *archiveServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.ranges...)
+}
+
+func testArchiveContent(size int) []byte {
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	return content
+}
+
+func TestDownloadArchive(t *testing.T) {
+	content := testArchiveContent(10000)
+	server := &archiveServer{content: content, flaky: true}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	var progress []int64
+	writer := &memoryWriterAt{}
+	archive := &Archive{Id: "arch1", Url: httpServer.URL + "/archive.mp4", Size: int64(len(content))}
+	err := DownloadArchive(context.Background(), archive, writer, DownloadOptions{
+		ChunkSize:   3000,
+		Concurrency: 2,
+		RetryDelay:  time.Millisecond,
+		OnProgress:  func(downloaded, size int64) { progress = append(progress, downloaded) },
+	})
+	if err != nil {
+		t.Fatalf("DownloadArchive() error = %v", err)
+	}
+	if !bytes.Equal(writer.data, content) {
+		t.Errorf("DownloadArchive() wrote %d bytes not matching the archive", len(writer.data))
+	}
+	if last := progress[len(progress)-1]; last != archive.Size {
+		t.Errorf("DownloadArchive() last progress = %d, want %d", last, archive.Size)
+	}
+	// every chunk is truncated halfway then resumed after the bytes received
+	resumed := map[string]bool{}
+	for _, r := range server.requests() {
+		resumed[r] = true
+	}
+	for _, want := range []string{"bytes=0-2999", "bytes=1500-2999", "bytes=9000-9999", "bytes=9500-9999"} {
+		if !resumed[want] {
+			t.Errorf("DownloadArchive() requests = %v, missing %v", server.requests(), want)
+		}
+	}
+}
+
+func TestDownloadArchive_errors(t *testing.T) {
+	content := testArchiveContent(1000)
+	httpServer := httptest.NewServer(&archiveServer{content: content, forbidden: map[string]bool{"bytes=500-999": true}})
+	defer httpServer.Close()
+	options := DownloadOptions{ChunkSize: 500, RetryDelay: time.Millisecond}
+
+	tests := []struct {
+		name    string
+		archive *Archive
+		wantErr error
+	}{
+		{"no_url", &Archive{Size: 1000}, ErrorArchiveNotAvailable},
+		{"no_archive", nil, ErrorArchiveNotAvailable},
+		{"size_mismatch", &Archive{Url: httpServer.URL, Size: 900}, ErrorArchiveSize},
+		{"expired_url", &Archive{Url: httpServer.URL, Size: 1000}, ErrorAuthentication},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := DownloadArchive(context.Background(), tt.archive, &memoryWriterAt{}, options); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DownloadArchive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDownloadArchiveFile_resume(t *testing.T) {
+	content := testArchiveContent(1000)
+	server := &archiveServer{content: content, forbidden: map[string]bool{"bytes=500-999": true}}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	path := filepath.Join(t.TempDir(), "archive.mp4")
+	archive := &Archive{Id: "arch1", Url: httpServer.URL, Size: int64(len(content))}
+	options := DownloadOptions{ChunkSize: 500, Concurrency: 1}
+	if err := DownloadArchiveFile(context.Background(), archive, path, options); !errors.Is(err, ErrorAuthentication) {
+		t.Fatalf("DownloadArchiveFile() error = %v, wantErr %v", err, ErrorAuthentication)
+	}
+	if _, err := os.Stat(path + ".part.json"); err != nil {
+		t.Fatalf("DownloadArchiveFile() did not save its progress: %v", err)
+	}
+
+	server.forbidden = nil
+	server.ranges = nil
+	if err := DownloadArchiveFile(context.Background(), archive, path, options); err != nil {
+		t.Fatalf("DownloadArchiveFile() error = %v", err)
+	}
+	if got := server.requests(); len(got) != 1 || got[0] != "bytes=500-999" {
+		t.Errorf("DownloadArchiveFile() requests = %v, want only the missing chunk", got)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil || !bytes.Equal(data, content) {
+		t.Errorf("DownloadArchiveFile() file does not match the archive, error = %v", err)
+	}
+	if _, err := os.Stat(path + ".part.json"); !os.IsNotExist(err) {
+		t.Errorf("DownloadArchiveFile() left its progress file, error = %v", err)
+	}
+}
+
+func TestDownloadArchiveFile_removedPart(t *testing.T) {
+	content := testArchiveContent(1000)
+	server := &archiveServer{content: content, forbidden: map[string]bool{"bytes=500-999": true}}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	path := filepath.Join(t.TempDir(), "archive.mp4")
+	archive := &Archive{Id: "arch1", Url: httpServer.URL, Size: int64(len(content))}
+	options := DownloadOptions{ChunkSize: 500, Concurrency: 1}
+	if err := DownloadArchiveFile(context.Background(), archive, path, options); !errors.Is(err, ErrorAuthentication) {
+		t.Fatalf("DownloadArchiveFile() error = %v, wantErr %v", err, ErrorAuthentication)
+	}
+	// the progress file says the first chunk is done but its bytes are gone
+	if err := os.Remove(path + ".part"); err != nil {
+		t.Fatal(err)
+	}
+
+	server.forbidden = nil
+	server.ranges = nil
+	if err := DownloadArchiveFile(context.Background(), archive, path, options); err != nil {
+		t.Fatalf("DownloadArchiveFile() error = %v", err)
+	}
+	if got, want := server.requests(), []string{"bytes=0-499", "bytes=500-999"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DownloadArchiveFile() requests = %v, want %v", got, want)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil || !bytes.Equal(data, content) {
+		t.Errorf("DownloadArchiveFile() file does not match the archive, error = %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package pkg
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Lists archives, implemented by {@link OpenTok OpenTok}.
+type ArchiveLister interface {
+	ListArchives(filter ArchiveFilter) (*ArchiveList, error)
+}
+
+// Selects archives of an ArchiveIndex, every field that is set must match.
+type ArchiveQuery struct {
+	SessionId string
+	// The exact archive name.
+	Name string
+	// Any of these statuses.
+	Statuses []ArchiveStatus
+	// Archives created at or after From and before To.
+	From time.Time
+	To   time.Time
+}
+
+func (q *ArchiveQuery) matches(archive *Archive) bool {
+	if len(q.SessionId) != 0 && archive.SessionId != q.SessionId {
+		return false
+	}
+	if len(q.Name) != 0 && archive.Name != q.Name {
+		return false
+	}
+	if len(q.Statuses) != 0 {
+		found := false
+		for _, status := range q.Statuses {
+			found = found || status == archive.Status
+		}
+		if !found {
+			return false
+		}
+	}
+	created := archive.CreatedTime()
+	if !q.From.IsZero() && created.Before(q.From) {
+		return false
+	}
+	return q.To.IsZero() || created.Before(q.To)
+}
+
+// A local copy of the archive metadata of a project, so that archives can be looked up
+// without listing them all. The index is kept up to date by Sync and archive status callbacks.
+type ArchiveIndex struct {
+	lister   ArchiveLister
+	store    ArchiveStore
+	pageSize int
+	// serializes the updates of the store
+	mu sync.Mutex
+}
+
+// Creates an ArchiveIndex.
+//
+// @param lister Usually the {@link OpenTok OpenTok} instance.
+// @param store Where the archives are kept, a MemoryArchiveStore or a FileArchiveStore.
+func NewArchiveIndex(lister ArchiveLister, store ArchiveStore) *ArchiveIndex {
+	return &ArchiveIndex{lister: lister, store: store, pageSize: 1000}
+}
+
+// Lists the archives created since the last sync, and the ones that were still recording or
+// being processed, and stores them. An empty index lists every archive.
+//
+// @return The number of archives listed.
+func (i *ArchiveIndex) Sync(ctx context.Context) (int, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	stored, err := i.store.All()
+	if err != nil {
+		return 0, err
+	}
+	// archives are listed newest first, the ones older than the newest archive stored and than
+	// every archive whose status can still change are known
+	var cutoff int64
+	for j, archive := range stored {
+		if j == 0 || archive.CreatedAt > cutoff {
+			cutoff = archive.CreatedAt
+		}
+	}
+	for _, archive := range stored {
+		if !archive.Status.Terminal() && archive.CreatedAt < cutoff {
+			cutoff = archive.CreatedAt
+		}
+	}
+	listed, err := i.list(ctx, func(page []*Archive) bool {
+		return len(stored) == 0 || page[len(page)-1].CreatedAt >= cutoff
+	})
+	return len(listed), err
+}
+
+// Lists every archive and replaces the content of the index, archives that are no longer
+// listed are removed.
+//
+// @return The number of archives listed.
+func (i *ArchiveIndex) Rebuild(ctx context.Context) (int, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	listed, err := i.list(ctx, func([]*Archive) bool { return true })
+	if err != nil {
+		return len(listed), err
+	}
+	stored, err := i.store.All()
+	if err != nil {
+		return len(listed), err
+	}
+	var removed []string
+	for _, archive := range stored {
+		if !listed[archive.Id] {
+			removed = append(removed, archive.Id)
+		}
+	}
+	if len(removed) != 0 {
+		err = i.store.Delete(removed...)
+	}
+	return len(listed), err
+}
+
+// stores the listed pages until the last one or until more returns false
+func (i *ArchiveIndex) list(ctx context.Context, more func(page []*Archive) bool) (map[string]bool, error) {
+	listed := make(map[string]bool)
+	for offset := 0; ; offset += i.pageSize {
+		if err := ctx.Err(); err != nil {
+			return listed, err
+		}
+		page, err := i.lister.ListArchives(ArchiveFilter{Offset: offset, Count: i.pageSize})
+		if err != nil {
+			return listed, err
+		}
+		items := make([]*Archive, 0, len(page.Items))
+		for _, archive := range page.Items {
+			if archive != nil {
+				items = append(items, archive)
+				listed[archive.Id] = true
+			}
+		}
+		if err := i.store.Put(items...); err != nil {
+			return listed, err
+		}
+		if len(items) == 0 || len(page.Items) < i.pageSize || offset+len(page.Items) >= page.Count || !more(items) {
+			return listed, nil
+		}
+	}
+}
+
+// Updates the index with the archive of an archive status callback. A callback that arrives
+// after a later status of the archive, e.g. "stopped" after "available", is ignored and a
+// deleted archive is removed.
+func (i *ArchiveIndex) HandleArchiveCallback(callback *ArchiveCallback) error {
+	if callback == nil || len(callback.Id) == 0 {
+		return ErrorMissingArchiveId
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	stored, err := i.store.Get(callback.Id)
+	if err != nil {
+		return err
+	}
+	if stored != nil && stored.Status != callback.Status && !stored.Status.CanTransitionTo(callback.Status) {
+		return nil
+	}
+	if callback.Status == ArchiveStatusDeleted {
+		return i.store.Delete(callback.Id)
+	}
+	archive := callback.Archive
+	return i.store.Put(&archive)
+}
+
+// Returns the archive with the given ID, nil when it is not indexed.
+func (i *ArchiveIndex) Get(archiveId string) (*Archive, error) {
+	return i.store.Get(archiveId)
+}
+
+// Returns the indexed archives matching the query, newest first.
+func (i *ArchiveIndex) Find(query ArchiveQuery) ([]*Archive, error) {
+	stored, err := i.store.All()
+	if err != nil {
+		return nil, err
+	}
+	var archives []*Archive
+	for _, archive := range stored {
+		if query.matches(archive) {
+			archives = append(archives, archive)
+		}
+	}
+	sort.Slice(archives, func(a, b int) bool {
+		if archives[a].CreatedAt != archives[b].CreatedAt {
+			return archives[a].CreatedAt > archives[b].CreatedAt
+		}
+		return archives[a].Id < archives[b].Id
+	})
+	return archives, nil
+}
@@ -0,0 +1,121 @@
+package pkg
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func archiveIds(archives []*Archive) []string {
+	var ids []string
+	for _, archive := range archives {
+		ids = append(ids, archive.Id)
+	}
+	return ids
+}
+
+func TestArchiveIndex(t *testing.T) {
+	start := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	archive := func(id, name, sessionId string, hours int, status ArchiveStatus) *Archive {
+		created := start.Add(time.Duration(hours) * time.Hour)
+		return &Archive{Id: id, Name: name, SessionId: sessionId, Status: status, CreatedAt: created.UnixNano() / int64(time.Millisecond)}
+	}
+	manager := &fakeArchiveManager{archives: []*Archive{
+		archive("a5", "standup", "1_b", 5, ArchiveStatusStarted),
+		archive("a4", "standup", "1_b", 4, ArchiveStatusAvailable),
+		archive("a3", "review", "1_a", 3, ArchiveStatusUploaded),
+		archive("a2", "standup", "1_a", 2, ArchiveStatusFailed),
+		archive("a1", "review", "1_a", 1, ArchiveStatusAvailable),
+	}}
+	index := NewArchiveIndex(manager, NewMemoryArchiveStore())
+	index.pageSize = 2
+
+	if n, err := index.Sync(context.Background()); err != nil || n != 5 || manager.lists != 3 {
+		t.Fatalf("Sync() = %d, %v with %d lists, want 5 archives in 3 lists", n, err, manager.lists)
+	}
+
+	// a new archive and a status change of the archive still recording
+	manager.archives = append([]*Archive{archive("a6", "review", "1_b", 6, ArchiveStatusAvailable)}, manager.archives...)
+	manager.archives[1] = archive("a5", "standup", "1_b", 5, ArchiveStatusAvailable)
+	manager.lists = 0
+	if n, err := index.Sync(context.Background()); err != nil || n != 4 || manager.lists != 2 {
+		t.Fatalf("Sync() = %d, %v with %d lists, want 4 archives in 2 lists", n, err, manager.lists)
+	}
+	if got, _ := index.Get("a5"); got == nil || got.Status != ArchiveStatusAvailable {
+		t.Errorf("Get() = %+v, want available", got)
+	}
+
+	callbacks := []*ArchiveCallback{
+		{Archive: *archive("a6", "review", "1_b", 6, ArchiveStatusExpired), Event: EventArchive},
+		// arrives after the archive is available
+		{Archive: *archive("a5", "standup", "1_b", 5, ArchiveStatusStopped), Event: EventArchive},
+		{Archive: *archive("a4", "standup", "1_b", 4, ArchiveStatusDeleted), Event: EventArchive},
+		{Archive: *archive("a7", "standup", "1_c", 7, ArchiveStatusStarted), Event: EventArchive},
+	}
+	for _, callback := range callbacks {
+		if err := index.HandleArchiveCallback(callback); err != nil {
+			t.Fatalf("HandleArchiveCallback() error = %v", err)
+		}
+	}
+	if err := index.HandleArchiveCallback(&ArchiveCallback{}); err != ErrorMissingArchiveId {
+		t.Errorf("HandleArchiveCallback() error = %v, want %v", err, ErrorMissingArchiveId)
+	}
+
+	tests := []struct {
+		name  string
+		query ArchiveQuery
+		want  []string
+	}{
+		{"all", ArchiveQuery{}, []string{"a7", "a6", "a5", "a3", "a2", "a1"}},
+		{"session", ArchiveQuery{SessionId: "1_a"}, []string{"a3", "a2", "a1"}},
+		{"name", ArchiveQuery{Name: "standup"}, []string{"a7", "a5", "a2"}},
+		{"statuses", ArchiveQuery{Statuses: []ArchiveStatus{ArchiveStatusAvailable, ArchiveStatusExpired}}, []string{"a6", "a5", "a1"}},
+		{"range", ArchiveQuery{From: start.Add(2 * time.Hour), To: start.Add(5 * time.Hour)}, []string{"a3", "a2"}},
+		{"combined", ArchiveQuery{SessionId: "1_a", Name: "review", From: start.Add(2 * time.Hour)}, []string{"a3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := index.Find(tt.query)
+			if err != nil || !reflect.DeepEqual(archiveIds(got), tt.want) {
+				t.Errorf("Find() = %v, %v, want %v", archiveIds(got), err, tt.want)
+			}
+		})
+	}
+
+	manager.archives = manager.archives[:len(manager.archives)-1]
+	if _, err := index.Rebuild(context.Background()); err != nil {
+		t.Fatalf("Rebuild() error = %v", err)
+	}
+	got, _ := index.Find(ArchiveQuery{})
+	if want := []string{"a6", "a5", "a4", "a3", "a2"}; !reflect.DeepEqual(archiveIds(got), want) {
+		t.Errorf("Rebuild() archives = %v, want %v", archiveIds(got), want)
+	}
+}
+
+func TestFileArchiveStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archives.json")
+	store, err := NewFileArchiveStore(path)
+	if err != nil {
+		t.Fatalf("NewFileArchiveStore() error = %v", err)
+	}
+	if err := store.Put(&Archive{Id: "a1", Status: ArchiveStatusAvailable}, &Archive{Id: "a2"}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := store.Delete("a2"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	reopened, err := NewFileArchiveStore(path)
+	if err != nil {
+		t.Fatalf("NewFileArchiveStore() error = %v", err)
+	}
+	all, _ := reopened.All()
+	if len(all) != 1 || all[0].Id != "a1" || all[0].Status != ArchiveStatusAvailable {
+		t.Errorf("All() = %v, want a1", archiveIds(all))
+	}
+	if got, err := reopened.Get("a2"); got != nil || err != nil {
+		t.Errorf("Get() = %+v, %v, want nil", got, err)
+	}
+}
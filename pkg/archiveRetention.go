@@ -0,0 +1,218 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrorNoRetentionPolicies = errors.New("at least one retention policy is required")
+
+// Lists and deletes archives, implemented by {@link OpenTok OpenTok}.
+type ArchiveManager interface {
+	ArchiveLister
+	DeleteArchive(archiveId string) error
+}
+
+// How long the archives matching a policy are kept. An archive matches when it matches every
+// selector that is set, a policy without selectors matches every archive.
+type RetentionPolicy struct {
+	// The policy name, reported with the archives it deleted.
+	Name string
+	// Matches the archive name, e.g. `^trial-`. Optional.
+	ArchiveName *regexp.Regexp
+	// Matches the archives of these sessions. Optional.
+	SessionIds []string
+	// Matches the archives for which it returns true. Optional.
+	Match func(archive *Archive) bool
+	// Archives older than this are deleted, 0 keeps the matching archives forever.
+	MaxAge time.Duration
+}
+
+func (p *RetentionPolicy) matches(archive *Archive) bool {
+	if p.ArchiveName != nil && !p.ArchiveName.MatchString(archive.Name) {
+		return false
+	}
+	if len(p.SessionIds) != 0 {
+		found := false
+		for _, sessionId := range p.SessionIds {
+			found = found || sessionId == archive.SessionId
+		}
+		if !found {
+			return false
+		}
+	}
+	return p.Match == nil || p.Match(archive)
+}
+
+type RetentionConfig struct {
+	// The policies, an archive is governed by the first one it matches and kept when it
+	// matches none. Put the specific policies, e.g. for trial tenants, before a catch-all.
+	Policies []*RetentionPolicy
+	// Reports the archives that would be deleted without deleting them.
+	DryRun bool
+	// Maximum number of DeleteArchive calls in flight (default 4).
+	Concurrency int
+	// Number of archives listed per ListArchives call, at most 1000 (default 1000).
+	PageSize int
+	// The current time, for testing (default time.Now).
+	Now func() time.Time
+}
+
+// An archive deleted, or to delete in dry-run mode, by a RetentionManager.
+type RetentionAction struct {
+	Archive *Archive
+	// The name of the policy that expired the archive.
+	Policy string
+	// The age of the archive when the policy was applied.
+	Age time.Duration
+	// Why DeleteArchive failed, nil otherwise.
+	Err error
+}
+
+type RetentionReport struct {
+	DryRun bool
+	// The number of archives listed.
+	Scanned int
+	// The archives deleted, or that would be deleted in dry-run mode, oldest first.
+	Deleted []*RetentionAction
+	// The archives that could not be deleted, oldest first.
+	Failed []*RetentionAction
+}
+
+// Deletes the archives that outlived their retention policy.
+type RetentionManager struct {
+	manager ArchiveManager
+	config  RetentionConfig
+}
+
+// Creates a RetentionManager.
+//
+// @param manager Usually the {@link OpenTok OpenTok} instance.
+// @param config The policies, dry-run mode and deletion concurrency.
+func NewRetentionManager(manager ArchiveManager, config RetentionConfig) (*RetentionManager, error) {
+	if len(config.Policies) == 0 {
+		return nil, ErrorNoRetentionPolicies
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 4
+	}
+	if config.PageSize <= 0 || config.PageSize > 1000 {
+		config.PageSize = 1000
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &RetentionManager{manager: manager, config: config}, nil
+}
+
+// Lists every archive of the project and deletes the ones older than the MaxAge of their
+// policy. Only the available, uploaded and expired archives are deleted, the other statuses do
+// not allow deletion.
+//
+// @param ctx Stops the run, deletions in flight complete.
+//
+// @return The report of the deleted archives. The error is the one of ListArchives, when an
+// archive cannot be listed nothing is deleted, or the one of ctx.
+func (m *RetentionManager) Run(ctx context.Context) (*RetentionReport, error) {
+	archives, err := m.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &RetentionReport{DryRun: m.config.DryRun, Scanned: len(archives)}
+
+	now := m.config.Now()
+	var expired []*RetentionAction
+	for _, archive := range archives {
+		if action := m.expired(archive, now); action != nil {
+			expired = append(expired, action)
+		}
+	}
+	sort.SliceStable(expired, func(i, j int) bool {
+		return expired[i].Archive.CreatedAt < expired[j].Archive.CreatedAt
+	})
+	if m.config.DryRun {
+		report.Deleted = expired
+		return report, nil
+	}
+
+	sem := make(chan struct{}, m.config.Concurrency)
+	var wg sync.WaitGroup
+	started := 0
+schedule:
+	for _, action := range expired {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break schedule
+		}
+		started++
+		wg.Add(1)
+		go func(action *RetentionAction) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			// an archive already gone counts as deleted
+			if err := m.manager.DeleteArchive(action.Archive.Id); err != nil && !errors.Is(err, ErrorArchiveNotFound) {
+				action.Err = err
+			}
+		}(action)
+	}
+	wg.Wait()
+
+	for _, action := range expired[:started] {
+		if action.Err != nil {
+			report.Failed = append(report.Failed, action)
+		} else {
+			report.Deleted = append(report.Deleted, action)
+		}
+	}
+	return report, ctx.Err()
+}
+
+// lists every archive, archives created while paging shift the pages so duplicates are dropped
+func (m *RetentionManager) list(ctx context.Context) ([]*Archive, error) {
+	var archives []*Archive
+	seen := make(map[string]bool)
+	for offset := 0; ; offset += m.config.PageSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := m.manager.ListArchives(ArchiveFilter{Offset: offset, Count: m.config.PageSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, archive := range page.Items {
+			if archive != nil && !seen[archive.Id] {
+				seen[archive.Id] = true
+				archives = append(archives, archive)
+			}
+		}
+		if len(page.Items) < m.config.PageSize || offset+len(page.Items) >= page.Count {
+			return archives, nil
+		}
+	}
+}
+
+func (m *RetentionManager) expired(archive *Archive, now time.Time) *RetentionAction {
+	// the statuses DeleteArchive accepts, the archives still recording or being uploaded and the
+	// failed ones are kept
+	switch archive.Status {
+	case ArchiveStatusAvailable, ArchiveStatusUploaded, ArchiveStatusExpired:
+	default:
+		return nil
+	}
+	for _, policy := range m.config.Policies {
+		if policy == nil || !policy.matches(archive) {
+			continue
+		}
+		age := now.Sub(archive.CreatedTime())
+		if policy.MaxAge <= 0 || age <= policy.MaxAge {
+			return nil
+		}
+		return &RetentionAction{Archive: archive, Policy: policy.Name, Age: age}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeArchiveManager struct {
+	mu       sync.Mutex
+	archives []*Archive
+	deleted  []string
+	failing  map[string]bool
+	lists    int
+	inFlight int
+	peak     int
+}
+
+func (f *fakeArchiveManager) ListArchives(filter ArchiveFilter) (*ArchiveList, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lists++
+	list := &ArchiveList{Count: len(f.archives)}
+	for i := filter.Offset; i < len(f.archives) && i < filter.Offset+filter.Count; i++ {
+		list.Items = append(list.Items, f.archives[i])
+	}
+	return list, nil
+}
+
+func (f *fakeArchiveManager) DeleteArchive(archiveId string) error {
+	f.mu.Lock()
+	f.inFlight++
+	if f.inFlight > f.peak {
+		f.peak = f.inFlight
+	}
+	f.mu.Unlock()
+	time.Sleep(time.Millisecond)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.inFlight--
+	if f.failing[archiveId] {
+		return ErrorServer
+	}
+	f.deleted = append(f.deleted, archiveId)
+	return nil
+}
+
+func TestRetentionManager_Run(t *testing.T) {
+	now := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	archive := func(id, name, sessionId string, days int, status ArchiveStatus) *Archive {
+		created := now.Add(-time.Duration(days) * 24 * time.Hour)
+		return &Archive{Id: id, Name: name, SessionId: sessionId, Status: status, CreatedAt: created.UnixNano() / int64(time.Millisecond)}
+	}
+	newManager := func() *fakeArchiveManager {
+		return &fakeArchiveManager{
+			archives: []*Archive{
+				archive("a1", "trial-acme", "1_a", 3, ArchiveStatusAvailable),
+				archive("a2", "trial-acme", "1_a", 8, ArchiveStatusAvailable),
+				archive("a3", "meeting", "1_b", 20, ArchiveStatusUploaded),
+				archive("a4", "meeting", "1_b", 31, ArchiveStatusAvailable),
+				archive("a5", "meeting", "1_legal", 400, ArchiveStatusAvailable),
+				archive("a6", "trial-other", "1_c", 10, ArchiveStatusFailed),
+				archive("a7", "meeting", "1_b", 45, ArchiveStatusExpired),
+				archive("a8", "meeting", "1_b", 60, ArchiveStatusStarted),
+				archive("a9", "meeting", "1_b", 50, ArchiveStatusStopped),
+			},
+			failing: map[string]bool{"a7": true},
+		}
+	}
+	config := RetentionConfig{
+		Policies: []*RetentionPolicy{
+			{Name: "legal-hold", SessionIds: []string{"1_legal"}},
+			{Name: "trial", ArchiveName: regexp.MustCompile(`^trial-`), MaxAge: 7 * 24 * time.Hour},
+			{Name: "default", MaxAge: 30 * 24 * time.Hour},
+		},
+		Concurrency: 2,
+		PageSize:    3,
+		Now:         func() time.Time { return now },
+	}
+	actions := func(actions []*RetentionAction) []string {
+		var got []string
+		for _, action := range actions {
+			got = append(got, action.Archive.Id+":"+action.Policy)
+		}
+		return got
+	}
+
+	manager := newManager()
+	config.DryRun = true
+	retention, err := NewRetentionManager(manager, config)
+	if err != nil {
+		t.Fatalf("NewRetentionManager() error = %v", err)
+	}
+	report, err := retention.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	wantDeleted := []string{"a7:default", "a4:default", "a2:trial"}
+	if got := actions(report.Deleted); !report.DryRun || report.Scanned != 9 || !reflect.DeepEqual(got, wantDeleted) {
+		t.Errorf("Run() dry run = %v scanned %d, want %v", got, report.Scanned, wantDeleted)
+	}
+	if len(manager.deleted) != 0 {
+		t.Errorf("Run() dry run deleted %v", manager.deleted)
+	}
+
+	config.DryRun = false
+	retention, _ = NewRetentionManager(manager, config)
+	report, err = retention.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got, want := actions(report.Deleted), []string{"a4:default", "a2:trial"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Run() deleted = %v, want %v", got, want)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].Archive.Id != "a7" || !errors.Is(report.Failed[0].Err, ErrorServer) {
+		t.Errorf("Run() failed = %v", actions(report.Failed))
+	}
+	sort.Strings(manager.deleted)
+	if want := []string{"a2", "a4"}; !reflect.DeepEqual(manager.deleted, want) {
+		t.Errorf("DeleteArchive() calls = %v, want %v", manager.deleted, want)
+	}
+	if manager.peak > config.Concurrency {
+		t.Errorf("DeleteArchive() concurrency = %d, want at most %d", manager.peak, config.Concurrency)
+	}
+
+	if report.Deleted[0].Age != 31*24*time.Hour {
+		t.Errorf("Run() age = %v, want 31 days", report.Deleted[0].Age)
+	}
+	if _, err := NewRetentionManager(manager, RetentionConfig{}); err != ErrorNoRetentionPolicies {
+		t.Errorf("NewRetentionManager() error = %v, want %v", err, ErrorNoRetentionPolicies)
+	}
+}
+
+func TestRetentionManager_paging(t *testing.T) {
+	manager := &fakeArchiveManager{}
+	for i := 0; i < 25; i++ {
+		manager.archives = append(manager.archives, &Archive{Id: fmt.Sprintf("a%d", i), Status: ArchiveStatusAvailable})
+	}
+	// a page shifted by a new archive repeats the last archive of the previous page
+	manager.archives = append(manager.archives[:10], append([]*Archive{manager.archives[9]}, manager.archives[10:]...)...)
+
+	retention, _ := NewRetentionManager(manager, RetentionConfig{
+		Policies: []*RetentionPolicy{{Name: "all", MaxAge: time.Hour}},
+		DryRun:   true,
+		PageSize: 10,
+	})
+	report, err := retention.Run(context.Background())
+	if err != nil || report.Scanned != 25 || len(report.Deleted) != 25 {
+		t.Errorf("Run() = %+v, %v, want 25 archives", report, err)
+	}
+}
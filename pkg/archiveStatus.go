@@ -0,0 +1,152 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// The status of an archive.
+type ArchiveStatus string
+
+const (
+	// The archive is recording.
+	ArchiveStatusStarted ArchiveStatus = "started"
+	// The archive is recording but no client publishes in the session.
+	ArchiveStatusPaused ArchiveStatus = "paused"
+	// The recording stopped and the archive is being processed.
+	ArchiveStatusStopped ArchiveStatus = "stopped"
+	// The archive was uploaded to the archive storage of the project.
+	ArchiveStatusUploaded ArchiveStatus = "uploaded"
+	// The archive can be downloaded from its Url.
+	ArchiveStatusAvailable ArchiveStatus = "available"
+	// The archive was available for more than 72 hours and was removed from the OpenTok cloud.
+	ArchiveStatusExpired ArchiveStatus = "expired"
+	// The archive could not be recorded, processed or uploaded, see its Reason.
+	ArchiveStatusFailed ArchiveStatus = "failed"
+	// The archive was deleted with {@link OpenTok#DeleteArchive OpenTok.DeleteArchive()}.
+	ArchiveStatusDeleted ArchiveStatus = "deleted"
+)
+
+// the statuses an archive can move to from each status
+var archiveTransitions = map[ArchiveStatus][]ArchiveStatus{
+	ArchiveStatusStarted:   {ArchiveStatusPaused, ArchiveStatusStopped, ArchiveStatusFailed},
+	ArchiveStatusPaused:    {ArchiveStatusStarted, ArchiveStatusStopped, ArchiveStatusFailed},
+	ArchiveStatusStopped:   {ArchiveStatusUploaded, ArchiveStatusAvailable, ArchiveStatusFailed},
+	ArchiveStatusUploaded:  {ArchiveStatusDeleted},
+	ArchiveStatusAvailable: {ArchiveStatusExpired, ArchiveStatusDeleted},
+	ArchiveStatusExpired:   {ArchiveStatusDeleted},
+	ArchiveStatusFailed:    {ArchiveStatusDeleted},
+}
+
+// Whether the status is one of the known ArchiveStatus* values.
+func (s ArchiveStatus) Valid() bool {
+	_, ok := archiveTransitions[s]
+	return ok || s == ArchiveStatusDeleted
+}
+
+// Whether the archive is recording, i.e. started or paused.
+func (s ArchiveStatus) Recording() bool {
+	return s == ArchiveStatusStarted || s == ArchiveStatusPaused
+}
+
+// Whether OpenTok is done with the archive: it is available, uploaded, failed, expired or
+// deleted and its status only changes when it expires or is deleted.
+func (s ArchiveStatus) Terminal() bool {
+	switch s {
+	case ArchiveStatusAvailable, ArchiveStatusUploaded, ArchiveStatusFailed, ArchiveStatusExpired, ArchiveStatusDeleted:
+		return true
+	}
+	return false
+}
+
+// Whether an archive can move from this status to the given one. An archive can skip
+// statuses between two observations, e.g. from started to available, so this is true when
+// the given status is reachable rather than only when it directly follows this one.
+func (s ArchiveStatus) CanTransitionTo(to ArchiveStatus) bool {
+	seen := map[ArchiveStatus]bool{s: true}
+	next := []ArchiveStatus{s}
+	for len(next) != 0 {
+		status := next[0]
+		next = next[1:]
+		for _, candidate := range archiveTransitions[status] {
+			if candidate == to {
+				return true
+			}
+			if !seen[candidate] {
+				seen[candidate] = true
+				next = append(next, candidate)
+			}
+		}
+	}
+	return false
+}
+
+type WaitArchiveOptions struct {
+	// The delay before the second poll, doubled after every poll (default 1s).
+	Interval time.Duration
+	// Upper bound of the delay between polls (default 30s).
+	MaxInterval time.Duration
+	// Called with the archive every time its status changes, including after the first poll.
+	// Optional.
+	OnStatus func(archive *Archive)
+}
+
+// Polls an archive until OpenTok is done with it, e.g. after
+// {@link OpenTok#StopArchive OpenTok.StopArchive()} to wait for it to be available.
+//
+// @param ctx Bounds the wait, polling stops as soon as it ends.
+// @param archiveId The archive ID.
+// @param options The polling intervals and status callback.
+//
+// @return The archive once its status is available, uploaded, failed, expired or deleted, see
+// {@link ArchiveStatus#Terminal ArchiveStatus.Terminal()}. A failed archive is not an error,
+// check its Status. Server errors and requests that got no response, see ErrorRequestFailed,
+// are retried until ctx ends, other errors of GetArchive are returned. When ctx ends first, the
+// error is the one of ctx and the archive is the last one polled, if any.
+func (ot *OpenTok) WaitForArchive(ctx context.Context, archiveId string, options WaitArchiveOptions) (*Archive, error) {
+	if len(archiveId) == 0 {
+		return nil, ErrorMissingArchiveId
+	}
+	if options.Interval <= 0 {
+		options.Interval = time.Second
+	}
+	if options.MaxInterval <= 0 {
+		options.MaxInterval = 30 * time.Second
+	}
+	if options.MaxInterval < options.Interval {
+		options.MaxInterval = options.Interval
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var last *Archive
+	delay := options.Interval
+	for {
+		archive, err := ot.GetArchive(archiveId)
+		switch {
+		case err == nil:
+			if options.OnStatus != nil && (last == nil || last.Status != archive.Status) {
+				options.OnStatus(archive)
+			}
+			last = archive
+			if archive.Status.Terminal() {
+				return archive, nil
+			}
+		case !errors.Is(err, ErrorServer) && !errors.Is(err, ErrorRequestFailed):
+			return last, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return last, ctx.Err()
+		}
+		if delay *= 2; delay > options.MaxInterval {
+			delay = options.MaxInterval
+		}
+	}
+}
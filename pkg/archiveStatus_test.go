@@ -0,0 +1,101 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestArchiveStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to ArchiveStatus
+		want     bool
+	}{
+		{ArchiveStatusStarted, ArchiveStatusPaused, true},
+		{ArchiveStatusPaused, ArchiveStatusStarted, true},
+		{ArchiveStatusStarted, ArchiveStatusAvailable, true},
+		{ArchiveStatusStopped, ArchiveStatusUploaded, true},
+		{ArchiveStatusAvailable, ArchiveStatusExpired, true},
+		{ArchiveStatusFailed, ArchiveStatusDeleted, true},
+		{ArchiveStatusAvailable, ArchiveStatusStarted, false},
+		{ArchiveStatusStopped, ArchiveStatusStarted, false},
+		{ArchiveStatusUploaded, ArchiveStatusExpired, false},
+		{ArchiveStatusDeleted, ArchiveStatusAvailable, false},
+		{ArchiveStatusStarted, ArchiveStatusStarted, true},
+		{ArchiveStatusStopped, ArchiveStatusStopped, false},
+		{"unknown", ArchiveStatusStarted, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%v.CanTransitionTo(%v) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+	if ArchiveStatus("unknown").Valid() || !ArchiveStatusDeleted.Valid() {
+		t.Errorf("Valid() does not match the known statuses")
+	}
+}
+
+func TestOpenTok_WaitForArchive(t *testing.T) {
+	var mu sync.Mutex
+	responses := map[string][]string{}
+	ot := newTestOpenTok(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		archiveId := r.URL.Path[len("/v2/project/"+testApiKey+"/archive/"):]
+		statuses := responses[archiveId]
+		if len(statuses) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		status := statuses[0]
+		if len(statuses) > 1 {
+			responses[archiveId] = statuses[1:]
+		}
+		if status == "error" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if status == "drop" {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"` + archiveId + `","status":"` + status + `"}`))
+	})
+	responses["arch1"] = []string{"started", "stopped", "error", "drop", "stopped", "available"}
+	responses["arch2"] = []string{"stopped", "failed"}
+	responses["arch3"] = []string{"started"}
+	options := WaitArchiveOptions{Interval: time.Millisecond, MaxInterval: 4 * time.Millisecond}
+
+	var seen []ArchiveStatus
+	options.OnStatus = func(archive *Archive) { seen = append(seen, archive.Status) }
+	got, err := ot.WaitForArchive(context.Background(), "arch1", options)
+	if err != nil || got.Status != ArchiveStatusAvailable {
+		t.Fatalf("WaitForArchive() = %+v, %v, want available", got, err)
+	}
+	if want := []ArchiveStatus{ArchiveStatusStarted, ArchiveStatusStopped, ArchiveStatusAvailable}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("WaitForArchive() statuses = %v, want %v", seen, want)
+	}
+	options.OnStatus = nil
+
+	if got, err := ot.WaitForArchive(context.Background(), "arch2", options); err != nil || got.Status != ArchiveStatusFailed {
+		t.Errorf("WaitForArchive() = %+v, %v, want failed", got, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if got, err := ot.WaitForArchive(ctx, "arch3", options); err != context.DeadlineExceeded || got == nil || got.Status != ArchiveStatusStarted {
+		t.Errorf("WaitForArchive() = %+v, %v, want started and %v", got, err, context.DeadlineExceeded)
+	}
+
+	if _, err := ot.WaitForArchive(context.Background(), "missing", options); !errors.Is(err, ErrorArchiveNotFound) {
+		t.Errorf("WaitForArchive() error = %v, want %v", err, ErrorArchiveNotFound)
+	}
+	if _, err := ot.WaitForArchive(context.Background(), "", options); err != ErrorMissingArchiveId {
+		t.Errorf("WaitForArchive() error = %v, want %v", err, ErrorMissingArchiveId)
+	}
+}
@@ -0,0 +1,209 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Where archives are uploaded.
+type ArchiveStorageType string
+
+const (
+	ArchiveStorageS3    ArchiveStorageType = "s3"
+	ArchiveStorageAzure ArchiveStorageType = "azure"
+)
+
+// What happens to an archive that cannot be uploaded.
+type ArchiveStorageFallback string
+
+const (
+	// The archive is lost.
+	ArchiveFallbackNone ArchiveStorageFallback = "none"
+	// The archive is kept by OpenTok and made available for download.
+	ArchiveFallbackOpenTok ArchiveStorageFallback = "opentok"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	ErrorArchiveStorage         = errors.New("invalid archive storage, an s3 target needs accessKey, secretKey and bucket, an azure target accountName, accountKey and container")
+	ErrorArchiveStorageFallback = errors.New("invalid archive storage fallback, must be none or opentok")
+	ErrorArchiveStorageNotFound = errors.New("no archive storage is configured")
+)
+
+// An Amazon S3 or S3-compatible upload target.
+type S3Config struct {
+	AccessKey string `json:"accessKey"`
+	SecretKey string `json:"secretKey"`
+	Bucket    string `json:"bucket"`
+	// The endpoint of an S3-compatible service, empty for Amazon S3.
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// Formats the target with the secret key redacted.
+func (c S3Config) String() string {
+	return fmt.Sprintf("{AccessKey:%s SecretKey:%s Bucket:%s Endpoint:%s}", c.AccessKey, redactSecret(c.SecretKey), c.Bucket, c.Endpoint)
+}
+
+func (c S3Config) GoString() string {
+	return "pkg.S3Config" + c.String()
+}
+
+// A Microsoft Azure Blob Storage upload target.
+type AzureConfig struct {
+	AccountName string `json:"accountName"`
+	AccountKey  string `json:"accountKey"`
+	Container   string `json:"container"`
+	// The domain of the storage account, empty for the default one.
+	Domain string `json:"domain,omitempty"`
+}
+
+// Formats the target with the account key redacted.
+func (c AzureConfig) String() string {
+	return fmt.Sprintf("{AccountName:%s AccountKey:%s Container:%s Domain:%s}", c.AccountName, redactSecret(c.AccountKey), c.Container, c.Domain)
+}
+
+func (c AzureConfig) GoString() string {
+	return "pkg.AzureConfig" + c.String()
+}
+
+// The upload target of the archives of the project. Exactly one of S3 and Azure is set,
+// matching Type.
+type ArchiveStorage struct {
+	Type     ArchiveStorageType
+	S3       *S3Config
+	Azure    *AzureConfig
+	Fallback ArchiveStorageFallback
+}
+
+// Formats the storage with its credentials redacted.
+func (s ArchiveStorage) String() string {
+	var config interface{}
+	switch {
+	case s.S3 != nil:
+		config = *s.S3
+	case s.Azure != nil:
+		config = *s.Azure
+	}
+	return fmt.Sprintf("{Type:%s Config:%v Fallback:%s}", s.Type, config, s.Fallback)
+}
+
+func (s ArchiveStorage) GoString() string {
+	return "pkg.ArchiveStorage" + s.String()
+}
+
+type archiveStorageJSON struct {
+	Type     ArchiveStorageType     `json:"type"`
+	Config   json.RawMessage        `json:"config"`
+	Fallback ArchiveStorageFallback `json:"fallback,omitempty"`
+}
+
+func (s ArchiveStorage) MarshalJSON() ([]byte, error) {
+	var config interface{}
+	switch s.Type {
+	case ArchiveStorageS3:
+		config = s.S3
+	case ArchiveStorageAzure:
+		config = s.Azure
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&archiveStorageJSON{Type: s.Type, Config: data, Fallback: s.Fallback})
+}
+
+func (s *ArchiveStorage) UnmarshalJSON(data []byte) error {
+	var decoded archiveStorageJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*s = ArchiveStorage{Type: decoded.Type, Fallback: decoded.Fallback}
+	if len(decoded.Config) == 0 {
+		return nil
+	}
+	switch decoded.Type {
+	case ArchiveStorageS3:
+		s.S3 = &S3Config{}
+		return json.Unmarshal(decoded.Config, s.S3)
+	case ArchiveStorageAzure:
+		s.Azure = &AzureConfig{}
+		return json.Unmarshal(decoded.Config, s.Azure)
+	}
+	return nil
+}
+
+func (s *ArchiveStorage) validate() error {
+	switch s.Type {
+	case ArchiveStorageS3:
+		if s.S3 == nil || s.Azure != nil || len(s.S3.AccessKey) == 0 || len(s.S3.SecretKey) == 0 || len(s.S3.Bucket) == 0 {
+			return ErrorArchiveStorage
+		}
+	case ArchiveStorageAzure:
+		if s.Azure == nil || s.S3 != nil || len(s.Azure.AccountName) == 0 || len(s.Azure.AccountKey) == 0 || len(s.Azure.Container) == 0 {
+			return ErrorArchiveStorage
+		}
+	default:
+		return ErrorArchiveStorage
+	}
+	switch s.Fallback {
+	case "", ArchiveFallbackNone, ArchiveFallbackOpenTok:
+		return nil
+	}
+	return ErrorArchiveStorageFallback
+}
+
+func redactSecret(secret string) string {
+	if len(secret) == 0 {
+		return ""
+	}
+	return redacted
+}
+
+func (c *Client) setArchiveStorage(storage *ArchiveStorage) error {
+	return c.request(http.MethodPut, c.endpointUrl(c.config.Endpoints.ArchiveStorage), storage, nil, nil)
+}
+
+func (c *Client) getArchiveStorage() (*ArchiveStorage, error) {
+	var storage ArchiveStorage
+	err := c.request(http.MethodGet, c.endpointUrl(c.config.Endpoints.ArchiveStorage), nil, &storage,
+		map[int]error{
+			http.StatusNotFound: ErrorArchiveStorageNotFound,
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &storage, nil
+}
+
+func (c *Client) deleteArchiveStorage() error {
+	return c.request(http.MethodDelete, c.endpointUrl(c.config.Endpoints.ArchiveStorage), nil, nil,
+		map[int]error{
+			http.StatusNotFound: ErrorArchiveStorageNotFound,
+		})
+}
+
+// Sets the S3 or Azure target the archives of the project are uploaded to.
+//
+// @param storage The upload target and the fallback used when an upload fails.
+func (ot *OpenTok) SetArchiveStorage(storage ArchiveStorage) error {
+	if err := storage.validate(); err != nil {
+		return err
+	}
+	return ot.client.setArchiveStorage(&storage)
+}
+
+// Gets the upload target of the archives of the project.
+//
+// @return The upload target. The error wraps ErrorArchiveStorageNotFound when none is set.
+func (ot *OpenTok) GetArchiveStorage() (*ArchiveStorage, error) {
+	return ot.client.getArchiveStorage()
+}
+
+// Removes the upload target of the archives of the project, archives are kept by OpenTok
+// again.
+func (ot *OpenTok) DeleteArchiveStorage() error {
+	return ot.client.deleteArchiveStorage()
+}
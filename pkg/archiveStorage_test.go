@@ -0,0 +1,99 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenTok_ArchiveStorage(t *testing.T) {
+	var stored []byte
+	ot := newTestOpenTok(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/project/"+testApiKey+"/archive/storage" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			stored, _ = ioutil.ReadAll(r.Body)
+		case http.MethodGet:
+			if stored == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(stored)
+		case http.MethodDelete:
+			stored = nil
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	storage := ArchiveStorage{
+		Type:     ArchiveStorageS3,
+		S3:       &S3Config{AccessKey: "AKIAEXAMPLE", SecretKey: "s3cr3t-value", Bucket: "archives", Endpoint: "https://minio.example.com"},
+		Fallback: ArchiveFallbackOpenTok,
+	}
+	if err := ot.SetArchiveStorage(storage); err != nil {
+		t.Fatalf("SetArchiveStorage() error = %v", err)
+	}
+	if want := `{"type":"s3","config":{"accessKey":"AKIAEXAMPLE","secretKey":"s3cr3t-value","bucket":"archives","endpoint":"https://minio.example.com"},"fallback":"opentok"}`; strings.TrimSpace(string(stored)) != want {
+		t.Errorf("SetArchiveStorage() request body = %s, want %s", stored, want)
+	}
+
+	got, err := ot.GetArchiveStorage()
+	if err != nil {
+		t.Fatalf("GetArchiveStorage() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, &storage) {
+		t.Errorf("GetArchiveStorage() = %v, want %v", got, storage)
+	}
+
+	if err := ot.DeleteArchiveStorage(); err != nil {
+		t.Fatalf("DeleteArchiveStorage() error = %v", err)
+	}
+	if _, err := ot.GetArchiveStorage(); !errors.Is(err, ErrorArchiveStorageNotFound) {
+		t.Errorf("GetArchiveStorage() error = %v, want %v", err, ErrorArchiveStorageNotFound)
+	}
+}
+
+func TestArchiveStorage_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage ArchiveStorage
+		wantErr error
+	}{
+		{"s3", ArchiveStorage{Type: ArchiveStorageS3, S3: &S3Config{AccessKey: "a", SecretKey: "s", Bucket: "b"}}, nil},
+		{"azure", ArchiveStorage{Type: ArchiveStorageAzure, Azure: &AzureConfig{AccountName: "a", AccountKey: "k", Container: "c"}, Fallback: ArchiveFallbackNone}, nil},
+		{"s3_missing_secret", ArchiveStorage{Type: ArchiveStorageS3, S3: &S3Config{AccessKey: "a", Bucket: "b"}}, ErrorArchiveStorage},
+		{"azure_with_s3_config", ArchiveStorage{Type: ArchiveStorageAzure, S3: &S3Config{AccessKey: "a", SecretKey: "s", Bucket: "b"}}, ErrorArchiveStorage},
+		{"unknown_type", ArchiveStorage{Type: "gcs"}, ErrorArchiveStorage},
+		{"unknown_fallback", ArchiveStorage{Type: ArchiveStorageS3, S3: &S3Config{AccessKey: "a", SecretKey: "s", Bucket: "b"}, Fallback: "retry"}, ErrorArchiveStorageFallback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.storage.validate(); err != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestArchiveStorage_String(t *testing.T) {
+	storage := ArchiveStorage{Type: ArchiveStorageS3, S3: &S3Config{AccessKey: "AKIAEXAMPLE", SecretKey: "s3cr3t-value", Bucket: "archives"}}
+	azure := ArchiveStorage{Type: ArchiveStorageAzure, Azure: &AzureConfig{AccountName: "account", AccountKey: "azur3-key", Container: "archives"}}
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		for _, value := range []interface{}{storage, &storage, *storage.S3, storage.S3, azure, *azure.Azure} {
+			formatted := fmt.Sprintf(format, value)
+			if strings.Contains(formatted, "s3cr3t-value") || strings.Contains(formatted, "azur3-key") {
+				t.Errorf("Sprintf(%q) = %s, leaks a credential", format, formatted)
+			}
+			if !strings.Contains(formatted, redacted) {
+				t.Errorf("Sprintf(%q) = %s, want %s", format, formatted, redacted)
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package pkg
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Keeps the archive metadata of an ArchiveIndex.
+type ArchiveStore interface {
+	// Adds or replaces archives, by ID.
+	Put(archives ...*Archive) error
+	// Returns the archive with the given ID, nil when it is not stored.
+	Get(archiveId string) (*Archive, error)
+	// Returns every stored archive, in no particular order.
+	All() ([]*Archive, error)
+	// Removes archives, unknown IDs are ignored.
+	Delete(archiveIds ...string) error
+}
+
+// An ArchiveStore keeping the archives in memory.
+type MemoryArchiveStore struct {
+	mu       sync.RWMutex
+	archives map[string]*Archive
+}
+
+func NewMemoryArchiveStore() *MemoryArchiveStore {
+	return &MemoryArchiveStore{archives: make(map[string]*Archive)}
+}
+
+func (s *MemoryArchiveStore) Put(archives ...*Archive) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, archive := range archives {
+		if archive != nil {
+			stored := *archive
+			s.archives[archive.Id] = &stored
+		}
+	}
+	return nil
+}
+
+func (s *MemoryArchiveStore) Get(archiveId string) (*Archive, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	archive, ok := s.archives[archiveId]
+	if !ok {
+		return nil, nil
+	}
+	stored := *archive
+	return &stored, nil
+}
+
+func (s *MemoryArchiveStore) All() ([]*Archive, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	archives := make([]*Archive, 0, len(s.archives))
+	for _, archive := range s.archives {
+		stored := *archive
+		archives = append(archives, &stored)
+	}
+	return archives, nil
+}
+
+func (s *MemoryArchiveStore) Delete(archiveIds ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, archiveId := range archiveIds {
+		delete(s.archives, archiveId)
+	}
+	return nil
+}
+
+// An ArchiveStore keeping the archives in memory and in a JSON file, rewritten on every change.
+type FileArchiveStore struct {
+	path   string
+	memory *MemoryArchiveStore
+	mu     sync.Mutex
+}
+
+type archiveStoreFile struct {
+	Archives []*Archive `json:"archives"`
+}
+
+// Opens the store saved at path, the file is created on the first change.
+func NewFileArchiveStore(path string) (*FileArchiveStore, error) {
+	s := &FileArchiveStore{path: path, memory: NewMemoryArchiveStore()}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file archiveStoreFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	_ = s.memory.Put(file.Archives...)
+	return s, nil
+}
+
+// writes the file next to the store then renames it, so a crash never leaves it half written
+func (s *FileArchiveStore) save() error {
+	archives, _ := s.memory.All()
+	data, err := json.Marshal(&archiveStoreFile{Archives: archives})
+	if err != nil {
+		return err
+	}
+	temp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := temp.Write(data); err != nil {
+		_ = temp.Close()
+		_ = os.Remove(temp.Name())
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		_ = os.Remove(temp.Name())
+		return err
+	}
+	return os.Rename(temp.Name(), s.path)
+}
+
+func (s *FileArchiveStore) Put(archives ...*Archive) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.memory.Put(archives...)
+	return s.save()
+}
+
+func (s *FileArchiveStore) Get(archiveId string) (*Archive, error) {
+	return s.memory.Get(archiveId)
+}
+
+func (s *FileArchiveStore) All() ([]*Archive, error) {
+	return s.memory.All()
+}
+
+func (s *FileArchiveStore) Delete(archiveIds ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.memory.Delete(archiveIds...)
+	return s.save()
+}
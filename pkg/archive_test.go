@@ -0,0 +1,279 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testArchiveJson = `{"id":"arch1","status":"started","name":"meeting","sessionId":"1_session","projectId":46513602,` +
+	`"partnerId":46513602,"size":0,"duration":0,"url":null,"createdAt":1585487337000,"outputMode":"composed",` +
+	`"resolution":"640x480","hasAudio":true,"hasVideo":true}`
+
+func TestOpenTok_StartArchive(t *testing.T) {
+	var gotBody map[string]interface{}
+	ot := newTestOpenTok(t, func(w http.ResponseWriter, r *http.Request) {
+		gotBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		switch {
+		case r.Method != http.MethodPost || r.URL.Path != "/v2/project/"+testApiKey+"/archive":
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case gotBody["sessionId"] == "1_recording":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"message":"Conflict. This session is already being recorded."}`))
+		case gotBody["sessionId"] != "1_session":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			_, _ = w.Write([]byte(testArchiveJson))
+		}
+	})
+	no := false
+	tests := []struct {
+		name      string
+		sessionId string
+		options   ArchiveOptions
+		wantBody  map[string]interface{}
+		wantErr   error
+	}{
+		{"defaults", "1_session", ArchiveOptions{}, map[string]interface{}{"sessionId": "1_session"}, nil},
+		{
+			"options",
+			"1_session",
+			ArchiveOptions{Name: "meeting", Resolution: "1280x720", HasAudio: &no},
+			map[string]interface{}{"sessionId": "1_session", "name": "meeting", "resolution": "1280x720", "hasAudio": false},
+			nil,
+		},
+		{"already_recording", "1_recording", ArchiveOptions{}, map[string]interface{}{"sessionId": "1_recording"}, ErrorArchiveConflict},
+		{"unknown_session", "1_other", ArchiveOptions{}, map[string]interface{}{"sessionId": "1_other"}, ErrorSessionNotFound},
+		{"no_media", "1_session", ArchiveOptions{HasAudio: &no, HasVideo: &no}, nil, ErrorArchiveNoMedia},
+		{
+			"layout",
+			"1_session",
+			ArchiveOptions{Layout: &Layout{Type: LayoutVerticalPresentation}},
+			map[string]interface{}{"sessionId": "1_session", "layout": map[string]interface{}{"type": "verticalPresentation"}},
+			nil,
+		},
+		{
+			"individual",
+			"1_session",
+			ArchiveOptions{OutputMode: ArchiveOutputIndividual},
+			map[string]interface{}{"sessionId": "1_session", "outputMode": "individual"},
+			nil,
+		},
+		{"individual_layout", "1_session", ArchiveOptions{OutputMode: ArchiveOutputIndividual, Layout: &Layout{Type: LayoutPip}}, nil, ErrorArchiveIndividualLayout},
+		{"individual_resolution", "1_session", ArchiveOptions{OutputMode: ArchiveOutputIndividual, Resolution: "640x480"}, nil, ErrorArchiveIndividualResolution},
+		{
+			"manual",
+			"1_session",
+			ArchiveOptions{StreamMode: ArchiveStreamManual},
+			map[string]interface{}{"sessionId": "1_session", "streamMode": "manual"},
+			nil,
+		},
+		{"unknown_stream_mode", "1_session", ArchiveOptions{StreamMode: "some"}, nil, ErrorArchiveStreamMode},
+		{
+			"transcription",
+			"1_session",
+			ArchiveOptions{
+				OutputMode:              ArchiveOutputIndividual,
+				HasTranscription:        true,
+				TranscriptionProperties: &TranscriptionProperties{PrimaryLanguageCode: "es-MX", HasSummary: true},
+			},
+			map[string]interface{}{
+				"sessionId":               "1_session",
+				"outputMode":              "individual",
+				"hasTranscription":        true,
+				"transcriptionProperties": map[string]interface{}{"primaryLanguageCode": "es-MX", "hasSummary": true},
+			},
+			nil,
+		},
+		{"transcription_composed", "1_session", ArchiveOptions{HasTranscription: true}, nil, ErrorTranscriptionOutputMode},
+		{"transcription_no_audio", "1_session", ArchiveOptions{OutputMode: ArchiveOutputIndividual, HasTranscription: true, HasAudio: &no}, nil, ErrorTranscriptionAudio},
+		{"transcription_properties_only", "1_session", ArchiveOptions{OutputMode: ArchiveOutputIndividual, TranscriptionProperties: &TranscriptionProperties{}}, nil, ErrorTranscriptionProperties},
+		{
+			"transcription_language",
+			"1_session",
+			ArchiveOptions{OutputMode: ArchiveOutputIndividual, HasTranscription: true, TranscriptionProperties: &TranscriptionProperties{PrimaryLanguageCode: "english"}},
+			nil,
+			ErrorTranscriptionLanguage,
+		},
+		{"unknown_output_mode", "1_session", ArchiveOptions{OutputMode: "mixed"}, nil, ErrorArchiveOutputMode},
+		{"invalid_layout", "1_session", ArchiveOptions{Layout: &Layout{Type: LayoutPip, StyleSheet: "stream {}"}}, nil, ErrorLayoutStyleSheet},
+		{
+			"quality",
+			"1_session",
+			ArchiveOptions{Resolution: Resolution1080x1920, MaxBitrate: 2000000, MultiArchiveTag: "hd"},
+			map[string]interface{}{"sessionId": "1_session", "resolution": "1080x1920", "maxBitrate": float64(2000000), "multiArchiveTag": "hd"},
+			nil,
+		},
+		{
+			"quantization",
+			"1_session",
+			ArchiveOptions{QuantizationParameter: ArchiveMinQuantization},
+			map[string]interface{}{"sessionId": "1_session", "quantizationParameter": float64(15)},
+			nil,
+		},
+		{"unknown_resolution", "1_session", ArchiveOptions{Resolution: "800x600"}, nil, ErrorArchiveResolution},
+		{"bitrate_and_quantization", "1_session", ArchiveOptions{MaxBitrate: 2000000, QuantizationParameter: 20}, nil, ErrorArchiveBitrateAndQuantization},
+		{"bitrate_too_low", "1_session", ArchiveOptions{MaxBitrate: 99999}, nil, ErrorArchiveMaxBitrate},
+		{"bitrate_too_high", "1_session", ArchiveOptions{MaxBitrate: 6000001}, nil, ErrorArchiveMaxBitrate},
+		{"quantization_too_low", "1_session", ArchiveOptions{QuantizationParameter: 14}, nil, ErrorArchiveQuantization},
+		{"quantization_too_high", "1_session", ArchiveOptions{QuantizationParameter: 41}, nil, ErrorArchiveQuantization},
+		{"individual_bitrate", "1_session", ArchiveOptions{OutputMode: ArchiveOutputIndividual, MaxBitrate: 2000000}, nil, ErrorArchiveIndividualQuality},
+		{"audio_only_quantization", "1_session", ArchiveOptions{HasVideo: &no, QuantizationParameter: 20}, nil, ErrorArchiveAudioOnlyQuality},
+		{"no_session_id", "", ArchiveOptions{}, nil, ErrorMissingSessionId},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotBody = nil
+			got, err := ot.StartArchive(tt.sessionId, tt.options)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("StartArchive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(gotBody, tt.wantBody) {
+				t.Errorf("StartArchive() request body = %v, want %v", gotBody, tt.wantBody)
+			}
+			if err == nil && (got.Id != "arch1" || got.CreatedTime().Unix() != 1585487337 || got.OutputMode != ArchiveOutputComposed) {
+				t.Errorf("StartArchive() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestOpenTok_archiveLifecycle(t *testing.T) {
+	var gotRequest string
+	ot := newTestOpenTok(t, func(w http.ResponseWriter, r *http.Request) {
+		gotRequest = r.Method + " " + strings.TrimPrefix(r.URL.Path, "/v2/project/"+testApiKey) + "?" + r.URL.RawQuery
+		switch gotRequest {
+		case "POST /archive/arch1/stop?", "GET /archive/arch1?":
+			_, _ = w.Write([]byte(testArchiveJson))
+		case "POST /archive/arch2/stop?":
+			w.WriteHeader(http.StatusConflict)
+		case "GET /archive?count=10&offset=5&sessionId=1_session":
+			_, _ = w.Write([]byte(`{"count":6,"items":[` + testArchiveJson + `]}`))
+		case "DELETE /archive/arch1?":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	tests := []struct {
+		name        string
+		call        func() (interface{}, error)
+		wantRequest string
+		wantErr     error
+	}{
+		{"stop", func() (interface{}, error) { return ot.StopArchive("arch1") }, "POST /archive/arch1/stop?", nil},
+		{"stop_not_recording", func() (interface{}, error) { return ot.StopArchive("arch2") }, "POST /archive/arch2/stop?", ErrorArchiveConflict},
+		{"stop_unknown", func() (interface{}, error) { return ot.StopArchive("arch3") }, "POST /archive/arch3/stop?", ErrorArchiveNotFound},
+		{"get", func() (interface{}, error) { return ot.GetArchive("arch1") }, "GET /archive/arch1?", nil},
+		{"get_unknown", func() (interface{}, error) { return ot.GetArchive("arch3") }, "GET /archive/arch3?", ErrorArchiveNotFound},
+		{"get_no_id", func() (interface{}, error) { return ot.GetArchive("") }, "", ErrorMissingArchiveId},
+		{
+			"list",
+			func() (interface{}, error) {
+				return ot.ListArchives(ArchiveFilter{Offset: 5, Count: 10, SessionId: "1_session"})
+			},
+			"GET /archive?count=10&offset=5&sessionId=1_session",
+			nil,
+		},
+		{"list_count", func() (interface{}, error) { return ot.ListArchives(ArchiveFilter{Count: 1001}) }, "", ErrorArchiveListCount},
+		{"delete", func() (interface{}, error) { return nil, ot.DeleteArchive("arch1") }, "DELETE /archive/arch1?", nil},
+		{"delete_unknown", func() (interface{}, error) { return nil, ot.DeleteArchive("arch3") }, "DELETE /archive/arch3?", ErrorArchiveNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotRequest = ""
+			if _, err := tt.call(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotRequest != tt.wantRequest {
+				t.Errorf("request = %v, want %v", gotRequest, tt.wantRequest)
+			}
+		})
+	}
+}
+
+func TestOpenTok_ArchiveStreams(t *testing.T) {
+	var gotBody map[string]interface{}
+	ot := newTestOpenTok(t, func(w http.ResponseWriter, r *http.Request) {
+		gotBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		switch {
+		case r.Method != http.MethodPatch:
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/v2/project/"+testApiKey+"/archive/auto/streams":
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.URL.Path != "/v2/project/"+testApiKey+"/archive/manual/streams":
+			w.WriteHeader(http.StatusNotFound)
+		case gotBody["addStream"] == "other" || gotBody["removeStream"] == "other":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	tests := []struct {
+		name     string
+		call     func() error
+		wantBody map[string]interface{}
+		wantErr  error
+	}{
+		{
+			"add",
+			func() error { return ot.AddArchiveStream("manual", "presenter", true, false) },
+			map[string]interface{}{"addStream": "presenter", "hasAudio": true, "hasVideo": false},
+			nil,
+		},
+		{
+			"remove",
+			func() error { return ot.RemoveArchiveStream("manual", "presenter") },
+			map[string]interface{}{"removeStream": "presenter"},
+			nil,
+		},
+		{
+			"auto_mode",
+			func() error { return ot.AddArchiveStream("auto", "presenter", true, true) },
+			map[string]interface{}{"addStream": "presenter", "hasAudio": true, "hasVideo": true},
+			ErrorArchiveNotManual,
+		},
+		{
+			"not_in_session",
+			func() error { return ot.RemoveArchiveStream("manual", "other") },
+			map[string]interface{}{"removeStream": "other"},
+			ErrorStreamNotInSession,
+		},
+		{
+			"unknown_archive",
+			func() error { return ot.RemoveArchiveStream("missing", "presenter") },
+			map[string]interface{}{"removeStream": "presenter"},
+			ErrorArchiveNotFound,
+		},
+		{"no_media", func() error { return ot.AddArchiveStream("manual", "presenter", false, false) }, nil, ErrorArchiveNoMedia},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotBody = nil
+			if err := tt.call(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(gotBody, tt.wantBody) {
+				t.Errorf("request body = %v, want %v", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestArchive_Transcription(t *testing.T) {
+	data := `{"id":"arch1","status":"available","outputMode":"individual","transcription":` +
+		`{"status":"available","url":"https://example.com/transcript.zip","primaryLanguageCode":"en-US","hasSummary":true}}`
+	var got Archive
+	if err := json.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := &Transcription{Status: "available", Url: "https://example.com/transcript.zip", PrimaryLanguageCode: "en-US", HasSummary: true}
+	if !reflect.DeepEqual(got.Transcription, want) {
+		t.Errorf("Transcription = %+v, want %+v", got.Transcription, want)
+	}
+}
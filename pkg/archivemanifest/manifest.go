@@ -0,0 +1,154 @@
+// Package archivemanifest reads the ZIP files of individual-stream archives and the JSON
+// manifest describing their stream files, and builds the timeline of the participants.
+package archivemanifest
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"time"
+
+	"github.com/VolodymyrPobochii/opentok-go/pkg"
+)
+
+var (
+	ErrorMissingManifest = errors.New("the archive has no manifest, expected a JSON file at the root of the ZIP")
+	ErrorFileNotFound    = errors.New("the stream file is not in the archive")
+)
+
+// The manifest of an individual-stream archive, the <archive ID>.json file of the ZIP.
+type Manifest struct {
+	// The archive ID.
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	// The session ID of the OpenTok session that was recorded.
+	SessionId string `json:"sessionId"`
+	// When the archive was started, in milliseconds since the UNIX epoch.
+	CreatedAt int64 `json:"createdAt"`
+	// One entry per recorded stream.
+	Files []*File `json:"files"`
+}
+
+// The time the archive was started.
+func (m *Manifest) CreatedTime() time.Time {
+	return time.Unix(0, m.CreatedAt*int64(time.Millisecond))
+}
+
+// A stream file of an individual-stream archive.
+type File struct {
+	// The stream ID.
+	StreamId string `json:"streamId"`
+	// The data of the connection that published the stream, see ParseConnectionData.
+	ConnectionData string `json:"connectionData"`
+	// The name of the file in the ZIP.
+	Filename string `json:"filename"`
+	// The size of the file in bytes.
+	Size int64 `json:"size"`
+	// When the stream started and stopped being recorded, in milliseconds since the start of
+	// the archive.
+	StartTimeOffset int64 `json:"startTimeOffset"`
+	StopTimeOffset  int64 `json:"stopTimeOffset"`
+	// Either pkg.VideoTypeCamera or pkg.VideoTypeScreen, empty for an audio-only stream.
+	VideoType string `json:"videoType"`
+}
+
+// When the stream started being recorded, since the start of the archive.
+func (f *File) Start() time.Duration {
+	return time.Duration(f.StartTimeOffset) * time.Millisecond
+}
+
+// When the stream stopped being recorded, since the start of the archive.
+func (f *File) Stop() time.Duration {
+	return time.Duration(f.StopTimeOffset) * time.Millisecond
+}
+
+// Decodes the connection data of the stream the same way as the callbacks, see
+// {@link pkg.ParseConnectionData pkg.ParseConnectionData()}.
+func (f *File) ParseConnectionData() (*pkg.ConnectionData, error) {
+	return pkg.ParseConnectionData(f.ConnectionData)
+}
+
+// Decodes a manifest.
+func Parse(r io.Reader) (*Manifest, error) {
+	var manifest Manifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// An opened individual-stream archive.
+type Archive struct {
+	Manifest *Manifest
+	reader   *zip.Reader
+	closer   io.Closer
+}
+
+// Opens the ZIP file of an individual-stream archive, e.g. one downloaded with
+// {@link pkg.DownloadArchiveFile pkg.DownloadArchiveFile()}. The archive must be closed.
+func Open(name string) (*Archive, error) {
+	zipFile, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := newArchive(&zipFile.Reader)
+	if err != nil {
+		_ = zipFile.Close()
+		return nil, err
+	}
+	archive.closer = zipFile
+	return archive, nil
+}
+
+// Reads an individual-stream archive from a ZIP of the given size.
+func NewReader(r io.ReaderAt, size int64) (*Archive, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return newArchive(reader)
+}
+
+func newArchive(reader *zip.Reader) (*Archive, error) {
+	for _, file := range reader.File {
+		if path.Dir(file.Name) != "." || path.Ext(file.Name) != ".json" {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		manifest, err := Parse(r)
+		_ = r.Close()
+		if err != nil {
+			return nil, err
+		}
+		return &Archive{Manifest: manifest, reader: reader}, nil
+	}
+	return nil, ErrorMissingManifest
+}
+
+// Opens the stream file of a manifest entry, the returned reader must be closed.
+func (a *Archive) OpenFile(file *File) (io.ReadCloser, error) {
+	for _, zipFile := range a.reader.File {
+		if zipFile.Name == file.Filename {
+			return zipFile.Open()
+		}
+	}
+	return nil, ErrorFileNotFound
+}
+
+// Builds the participant timeline of the archive, see NewTimeline.
+func (a *Archive) Timeline() *Timeline {
+	return NewTimeline(a.Manifest)
+}
+
+// Closes the ZIP file of an archive opened with Open.
+func (a *Archive) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
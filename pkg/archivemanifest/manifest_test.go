@@ -0,0 +1,161 @@
+package archivemanifest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func connectionData(uid string) string {
+	return base64.StdEncoding.EncodeToString([]byte(uid + "&chat1&call1"))
+}
+
+func testManifest() string {
+	return `{"id":"arch1","name":"meeting","sessionId":"1_session","createdAt":1585487337000,"files":[` +
+		`{"streamId":"s2","connectionData":"` + connectionData("bob") + `","filename":"s2.webm","size":3,"startTimeOffset":2000,"stopTimeOffset":5000,"videoType":"camera"},` +
+		`{"streamId":"s1","connectionData":"` + connectionData("alice") + `","filename":"s1.webm","size":2,"startTimeOffset":0,"stopTimeOffset":4000,"videoType":"camera"},` +
+		`{"streamId":"s3","connectionData":"` + connectionData("alice") + `","filename":"s3.webm","size":1,"startTimeOffset":3000,"stopTimeOffset":6000,"videoType":"screen"},` +
+		`{"streamId":"s4","connectionData":"` + connectionData("alice") + `","filename":"s4.webm","size":1,"startTimeOffset":8000,"stopTimeOffset":9000,"videoType":"camera"},` +
+		`{"streamId":"s5","connectionData":"guest","filename":"s5.webm","size":1,"startTimeOffset":1000,"stopTimeOffset":2000,"videoType":""}]}`
+}
+
+func testZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestOpen(t *testing.T) {
+	data := testZip(t, map[string]string{"arch1.json": testManifest(), "s1.webm": "s1", "s2.webm": "s2!"})
+	path := filepath.Join(t.TempDir(), "arch1.zip")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer archive.Close()
+
+	manifest := archive.Manifest
+	if manifest.Id != "arch1" || manifest.CreatedTime().Unix() != 1585487337 || len(manifest.Files) != 5 {
+		t.Fatalf("Open() manifest = %+v", manifest)
+	}
+	file := manifest.Files[0]
+	if file.StreamId != "s2" || file.Start() != 2*time.Second || file.Stop() != 5*time.Second || file.VideoType != "camera" {
+		t.Errorf("Open() file = %+v", file)
+	}
+	if data, err := file.ParseConnectionData(); err != nil || data.Uid != "bob" || data.ChatId != "chat1" {
+		t.Errorf("ParseConnectionData() = %+v, %v", data, err)
+	}
+
+	r, err := archive.OpenFile(file)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	content, _ := ioutil.ReadAll(r)
+	_ = r.Close()
+	if string(content) != "s2!" {
+		t.Errorf("OpenFile() content = %q, want %q", content, "s2!")
+	}
+	if _, err := archive.OpenFile(manifest.Files[2]); err != ErrorFileNotFound {
+		t.Errorf("OpenFile() error = %v, want %v", err, ErrorFileNotFound)
+	}
+
+	data = testZip(t, map[string]string{"s1.webm": "s1", "nested/arch1.json": testManifest()})
+	if _, err := NewReader(bytes.NewReader(data), int64(len(data))); err != ErrorMissingManifest {
+		t.Errorf("NewReader() error = %v, want %v", err, ErrorMissingManifest)
+	}
+}
+
+func TestNewTimeline(t *testing.T) {
+	data := testZip(t, map[string]string{"arch1.json": testManifest()})
+	archive, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	timeline := archive.Timeline()
+
+	if timeline.Duration != 9*time.Second {
+		t.Errorf("Duration = %v, want 9s", timeline.Duration)
+	}
+	var keys []string
+	for _, participant := range timeline.Participants {
+		keys = append(keys, participant.Key)
+	}
+	if want := []string{"uid:alice", "data:guest", "uid:bob"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("Participants = %v, want %v", keys, want)
+	}
+
+	alice := timeline.Participants[0]
+	wantIntervals := []Interval{{0, 6 * time.Second}, {8 * time.Second, 9 * time.Second}}
+	if !reflect.DeepEqual(alice.Intervals, wantIntervals) || alice.Duration() != 7*time.Second || len(alice.Files) != 3 {
+		t.Errorf("alice = %+v, want intervals %v", alice, wantIntervals)
+	}
+	if guest := timeline.Participants[1]; guest.ConnectionData != nil || len(guest.Files) != 1 {
+		t.Errorf("guest = %+v, want undecoded connection data", guest)
+	}
+
+	tests := []struct {
+		offset time.Duration
+		want   []string
+	}{
+		{500 * time.Millisecond, []string{"uid:alice"}},
+		{1500 * time.Millisecond, []string{"uid:alice", "data:guest"}},
+		{5500 * time.Millisecond, []string{"uid:alice"}},
+		{7 * time.Second, nil},
+		{8500 * time.Millisecond, []string{"uid:alice"}},
+		{9 * time.Second, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, participant := range timeline.At(tt.offset) {
+			got = append(got, participant.Key)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("At(%v) = %v, want %v", tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestNewTimeline_anonymous(t *testing.T) {
+	timeline := NewTimeline(&Manifest{Files: []*File{
+		{StreamId: "s1", StartTimeOffset: 0, StopTimeOffset: 2000},
+		{StreamId: "s2", StartTimeOffset: 1000, StopTimeOffset: 4000},
+		// raw connection data equal to a uid
+		{StreamId: "s3", ConnectionData: "alice", StartTimeOffset: 0, StopTimeOffset: 1000},
+		{StreamId: "s4", ConnectionData: connectionData("alice"), StartTimeOffset: 3000, StopTimeOffset: 5000},
+	}})
+
+	var keys []string
+	for _, participant := range timeline.Participants {
+		keys = append(keys, participant.Key)
+	}
+	if want := []string{"stream:s1", "data:alice", "stream:s2", "uid:alice"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("Participants = %v, want %v", keys, want)
+	}
+	if got := timeline.At(1500 * time.Millisecond); len(got) != 2 {
+		t.Errorf("At(1.5s) = %d participants, want 2", len(got))
+	}
+	if duration := timeline.Participants[0].Duration(); duration != 2*time.Second {
+		t.Errorf("Duration() = %v, want 2s", duration)
+	}
+}
@@ -0,0 +1,126 @@
+package archivemanifest
+
+import (
+	"sort"
+	"time"
+
+	"github.com/VolodymyrPobochii/opentok-go/pkg"
+)
+
+// A period during which a participant was recorded, since the start of the archive.
+type Interval struct {
+	Start time.Duration
+	Stop  time.Duration
+}
+
+// A participant of the archive, the streams published by one user.
+type Participant struct {
+	// Identifies the participant: "uid:" followed by the uid of the connection data, "data:"
+	// followed by the raw connection data when it does not decode, or "stream:" followed by the
+	// stream ID when the stream has no connection data.
+	Key string
+	// The decoded connection data, nil when it does not decode.
+	ConnectionData *pkg.ConnectionData
+	// The stream files of the participant, by start offset.
+	Files []*File
+	// When at least one stream of the participant was recorded, overlapping streams merged.
+	Intervals []Interval
+}
+
+// Whether a stream of the participant was recorded at the given offset.
+func (p *Participant) Present(offset time.Duration) bool {
+	for _, interval := range p.Intervals {
+		if offset >= interval.Start && offset < interval.Stop {
+			return true
+		}
+	}
+	return false
+}
+
+// How long the participant was recorded in total.
+func (p *Participant) Duration() time.Duration {
+	var duration time.Duration
+	for _, interval := range p.Intervals {
+		duration += interval.Stop - interval.Start
+	}
+	return duration
+}
+
+// Who was recorded when in an individual-stream archive.
+type Timeline struct {
+	// The participants, by first appearance.
+	Participants []*Participant
+	// The offset of the last stream stop.
+	Duration time.Duration
+}
+
+// Groups the stream files of a manifest by participant. Streams whose connection data decodes
+// are grouped by uid, so a participant who reconnected or also shared their screen appears
+// once, other streams are grouped by their raw connection data. A stream without connection data
+// is a participant of its own.
+func NewTimeline(manifest *Manifest) *Timeline {
+	files := make([]*File, 0, len(manifest.Files))
+	for _, file := range manifest.Files {
+		if file != nil {
+			files = append(files, file)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].StartTimeOffset < files[j].StartTimeOffset
+	})
+
+	timeline := &Timeline{}
+	participants := make(map[string]*Participant)
+	for _, file := range files {
+		data, _ := file.ParseConnectionData()
+		key := participantKey(file, data)
+		participant, ok := participants[key]
+		if !ok {
+			participant = &Participant{Key: key, ConnectionData: data}
+			participants[key] = participant
+			timeline.Participants = append(timeline.Participants, participant)
+		}
+		participant.Files = append(participant.Files, file)
+		participant.addInterval(Interval{Start: file.Start(), Stop: file.Stop()})
+		if file.Stop() > timeline.Duration {
+			timeline.Duration = file.Stop()
+		}
+	}
+	return timeline
+}
+
+// prefixed so that a raw connection data never collides with a uid or a stream ID
+func participantKey(file *File, data *pkg.ConnectionData) string {
+	switch {
+	case data != nil:
+		return "uid:" + data.Uid
+	case len(file.ConnectionData) != 0:
+		return "data:" + file.ConnectionData
+	}
+	return "stream:" + file.StreamId
+}
+
+// merges an interval starting at or after the previous ones
+func (p *Participant) addInterval(interval Interval) {
+	if interval.Stop < interval.Start {
+		interval.Stop = interval.Start
+	}
+	if last := len(p.Intervals) - 1; last >= 0 && interval.Start <= p.Intervals[last].Stop {
+		if interval.Stop > p.Intervals[last].Stop {
+			p.Intervals[last].Stop = interval.Stop
+		}
+		return
+	}
+	p.Intervals = append(p.Intervals, interval)
+}
+
+// The participants recorded at the given offset.
+func (t *Timeline) At(offset time.Duration) []*Participant {
+	var present []*Participant
+	for _, participant := range t.Participants {
+		if participant.Present(offset) {
+			present = append(present, participant)
+		}
+	}
+	return present
+}
@@ -0,0 +1,289 @@
+// Package autoarchive starts and stops the archives of sessions created with the manual archive
+// mode from their stream callbacks, so that a meeting is recorded once its hosts are there
+// rather than from the first stream as with the always archive mode.
+package autoarchive
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/VolodymyrPobochii/opentok-go/pkg"
+)
+
+// Starts and stops archives, implemented by {@link pkg.OpenTok OpenTok}.
+type Archiver interface {
+	StartArchive(sessionId string, options pkg.ArchiveOptions) (*pkg.Archive, error)
+	StopArchive(archiveId string) (*pkg.Archive, error)
+}
+
+// What the orchestrator did with the archive of a session.
+type Action string
+
+const (
+	ActionStart Action = "start"
+	ActionStop  Action = "stop"
+)
+
+// The streams of a session, as known from its callbacks.
+type SessionState struct {
+	SessionId string
+	Streams   map[string]*pkg.Stream
+	isHost    func(stream *pkg.Stream) bool
+	// streams destroyed, a streamCreated callback arriving after their streamDestroyed one is
+	// ignored. Stream IDs are never reused so it only grows, until the session is forgotten.
+	destroyed map[string]bool
+	// the archive being recorded, empty when not recording
+	archiveId string
+}
+
+// The number of streams published.
+func (s *SessionState) Publishers() int {
+	return len(s.Streams)
+}
+
+// The number of streams published by hosts.
+func (s *SessionState) Hosts() int {
+	hosts := 0
+	for _, stream := range s.Streams {
+		if s.isHost(stream) {
+			hosts++
+		}
+	}
+	return hosts
+}
+
+// Decides whether recording starts, evaluated after every callback of a session that is not
+// recorded and by {@link Orchestrator#Recheck Orchestrator.Recheck()}.
+type Condition func(state *SessionState) bool
+
+// Met once a host publishes.
+func HostPresent() Condition {
+	return func(state *SessionState) bool {
+		return state.Hosts() > 0
+	}
+}
+
+// Met once at least count streams are published.
+func MinPublishers(count int) Condition {
+	return func(state *SessionState) bool {
+		return state.Publishers() >= count
+	}
+}
+
+// Met when one of the conditions is met.
+func AnyOf(conditions ...Condition) Condition {
+	return func(state *SessionState) bool {
+		for _, condition := range conditions {
+			if condition(state) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Met when all the conditions are met.
+func AllOf(conditions ...Condition) Condition {
+	return func(state *SessionState) bool {
+		for _, condition := range conditions {
+			if !condition(state) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+type Config struct {
+	// When recording starts (default HostPresent).
+	Start Condition
+	// Whether a stream is published by a host, e.g. from its connection data. Recording stops
+	// when the last host stream is destroyed or when no stream is left (default every stream
+	// is a host). The streams of a {@link pkg.StreamWatcher StreamWatcher} have a nil
+	// Connection, decide from their Name instead.
+	IsHost func(stream *pkg.Stream) bool
+	// The options of the started archives.
+	Options pkg.ArchiveOptions
+}
+
+// The archive started or stopped after a callback.
+type Result struct {
+	SessionId string
+	Action    Action
+	// The archive returned by StartArchive or StopArchive, nil when it failed.
+	Archive *pkg.Archive
+	// Why StartArchive or StopArchive failed. A failed start is retried by the next callback of
+	// the session or by {@link Orchestrator#Recheck Orchestrator.Recheck()}.
+	Err error
+}
+
+type session struct {
+	// held while the callbacks of the session are handled, including the archive calls, so
+	// concurrent callbacks of a session never start two archives
+	mu    sync.Mutex
+	state *SessionState
+}
+
+// Starts and stops the archives of sessions created with the manual archive mode.
+type Orchestrator struct {
+	archiver Archiver
+	config   Config
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// Creates an Orchestrator.
+//
+// @param archiver Usually the {@link pkg.OpenTok OpenTok} instance.
+// @param config The start condition, host detection and archive options.
+func NewOrchestrator(archiver Archiver, config Config) *Orchestrator {
+	if config.Start == nil {
+		config.Start = HostPresent()
+	}
+	if config.IsHost == nil {
+		config.IsHost = func(*pkg.Stream) bool { return true }
+	}
+	return &Orchestrator{archiver: archiver, config: config, sessions: make(map[string]*session)}
+}
+
+func (o *Orchestrator) session(sessionId string) *session {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	s, ok := o.sessions[sessionId]
+	if !ok {
+		s = &session{state: &SessionState{
+			SessionId: sessionId,
+			Streams:   make(map[string]*pkg.Stream),
+			isHost:    o.config.IsHost,
+			destroyed: make(map[string]bool),
+		}}
+		o.sessions[sessionId] = s
+	}
+	return s
+}
+
+// Updates the session with a streamCreated/streamDestroyed callback and starts or stops its
+// archive. Duplicate callbacks have no effect and a streamCreated callback arriving after the
+// streamDestroyed one of the same stream is ignored.
+//
+// @return What was done with the archive, nil when nothing was.
+func (o *Orchestrator) HandleStreamCallback(callback *pkg.StreamCallback) *Result {
+	if callback == nil || callback.Stream == nil || len(callback.Stream.ID) == 0 {
+		return nil
+	}
+	s := o.session(callback.SessionID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.state
+	streamId := callback.Stream.ID
+
+	switch callback.Event {
+	case pkg.EventStreamCreated:
+		if state.destroyed[streamId] {
+			return nil
+		}
+		if _, ok := state.Streams[streamId]; ok {
+			return nil
+		}
+		state.Streams[streamId] = callback.Stream
+		return o.recheck(state)
+	case pkg.EventStreamDestroyed:
+		state.destroyed[streamId] = true
+		// the stream of the streamCreated callback, the one IsHost was applied to
+		stream, ok := state.Streams[streamId]
+		if !ok {
+			return nil
+		}
+		delete(state.Streams, streamId)
+		lastHost := o.config.IsHost(stream) && state.Hosts() == 0
+		if len(state.archiveId) != 0 && (lastHost || state.Publishers() == 0) {
+			return o.stop(state)
+		}
+		// retries a start that failed, e.g. on a server error
+		return o.recheck(state)
+	}
+	return nil
+}
+
+// Updates the session with an archive status callback, so that an archive stopped by other
+// means, e.g. when the session ends or by the archive duration limit, is no longer tracked.
+// A new archive starts right away when the start condition is still met, e.g. the hosts are
+// still there.
+//
+// @return The archive started, nil when none was.
+func (o *Orchestrator) HandleArchiveCallback(callback *pkg.ArchiveCallback) *Result {
+	if callback == nil || callback.Status.Recording() {
+		return nil
+	}
+	s := o.session(callback.SessionId)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state.archiveId != callback.Id {
+		return nil
+	}
+	s.state.archiveId = ""
+	return o.recheck(s.state)
+}
+
+// Evaluates the start condition of a session that is not recorded and starts its archive when
+// it is met, e.g. to retry after StartArchive failed rather than waiting for the next callback
+// of the session.
+//
+// @return The archive started, nil when none was.
+func (o *Orchestrator) Recheck(sessionId string) *Result {
+	o.mu.Lock()
+	s, ok := o.sessions[sessionId]
+	o.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return o.recheck(s.state)
+}
+
+func (o *Orchestrator) recheck(state *SessionState) *Result {
+	if len(state.archiveId) != 0 || state.Publishers() == 0 || !o.config.Start(state) {
+		return nil
+	}
+	return o.start(state)
+}
+
+// Returns the ID of the archive recording the session, empty when it is not recorded.
+func (o *Orchestrator) Archive(sessionId string) string {
+	o.mu.Lock()
+	s, ok := o.sessions[sessionId]
+	o.mu.Unlock()
+	if !ok {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.archiveId
+}
+
+// Forgets the state of a session, e.g. once the session ended. The state of a session is kept
+// until then, including the IDs of its destroyed streams, so long running orchestrators should
+// forget the sessions that ended.
+func (o *Orchestrator) Forget(sessionId string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.sessions, sessionId)
+}
+
+func (o *Orchestrator) start(state *SessionState) *Result {
+	archive, err := o.archiver.StartArchive(state.SessionId, o.config.Options)
+	if err == nil {
+		state.archiveId = archive.Id
+	}
+	return &Result{SessionId: state.SessionId, Action: ActionStart, Archive: archive, Err: err}
+}
+
+func (o *Orchestrator) stop(state *SessionState) *Result {
+	archive, err := o.archiver.StopArchive(state.archiveId)
+	// an archive that is not found or no longer recording was stopped by other means
+	if err == nil || errors.Is(err, pkg.ErrorArchiveConflict) || errors.Is(err, pkg.ErrorArchiveNotFound) {
+		state.archiveId = ""
+	}
+	return &Result{SessionId: state.SessionId, Action: ActionStop, Archive: archive, Err: err}
+}
@@ -0,0 +1,189 @@
+package autoarchive
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/VolodymyrPobochii/opentok-go/pkg"
+)
+
+type fakeArchiver struct {
+	mu       sync.Mutex
+	started  int
+	calls    []string
+	startErr error
+	stopErr  error
+}
+
+func (f *fakeArchiver) StartArchive(sessionId string, options pkg.ArchiveOptions) (*pkg.Archive, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.started++
+	archiveId := fmt.Sprintf("arch%d", f.started)
+	f.calls = append(f.calls, "start "+sessionId+" "+options.Name)
+	if f.startErr != nil {
+		return nil, f.startErr
+	}
+	return &pkg.Archive{Id: archiveId, SessionId: sessionId, Status: pkg.ArchiveStatusStarted}, nil
+}
+
+func (f *fakeArchiver) StopArchive(archiveId string) (*pkg.Archive, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, "stop "+archiveId)
+	if f.stopErr != nil {
+		return nil, f.stopErr
+	}
+	return &pkg.Archive{Id: archiveId, Status: pkg.ArchiveStatusStopped}, nil
+}
+
+func streamCallback(event, streamId, name string) *pkg.StreamCallback {
+	return &pkg.StreamCallback{
+		Callback: pkg.Callback{SessionID: "1_session", Event: event},
+		Stream:   &pkg.Stream{ID: streamId, Name: name},
+	}
+}
+
+func isHost(stream *pkg.Stream) bool {
+	return stream.Name == "host"
+}
+
+func TestOrchestrator_host(t *testing.T) {
+	archiver := &fakeArchiver{}
+	orchestrator := NewOrchestrator(archiver, Config{IsHost: isHost, Options: pkg.ArchiveOptions{Name: "meeting"}})
+
+	callbacks := []struct {
+		callback *pkg.StreamCallback
+		want     Action
+	}{
+		// green room
+		{streamCallback(pkg.EventStreamCreated, "guest1", "guest"), ""},
+		{streamCallback(pkg.EventStreamCreated, "host1", "host"), ActionStart},
+		{streamCallback(pkg.EventStreamCreated, "host1", "host"), ""},
+		{streamCallback(pkg.EventStreamCreated, "host2", "host"), ""},
+		{streamCallback(pkg.EventStreamDestroyed, "host1", "host"), ""},
+		{streamCallback(pkg.EventStreamDestroyed, "host1", "host"), ""},
+		// the streamCreated callback of host1 delivered late
+		{streamCallback(pkg.EventStreamCreated, "host1", "host"), ""},
+		{streamCallback(pkg.EventStreamDestroyed, "guest1", "guest"), ""},
+		{streamCallback(pkg.EventStreamDestroyed, "host2", "host"), ActionStop},
+		{streamCallback(pkg.EventStreamDestroyed, "host2", "host"), ""},
+		// destroyed before created
+		{streamCallback(pkg.EventStreamDestroyed, "host3", "host"), ""},
+		{streamCallback(pkg.EventStreamCreated, "host3", "host"), ""},
+		{streamCallback(pkg.EventStreamCreated, "host4", "host"), ActionStart},
+	}
+	for i, c := range callbacks {
+		result := orchestrator.HandleStreamCallback(c.callback)
+		var got Action
+		if result != nil {
+			got = result.Action
+			if result.Err != nil {
+				t.Errorf("callback %d error = %v", i, result.Err)
+			}
+		}
+		if got != c.want {
+			t.Errorf("callback %d %s %s = %q, want %q", i, c.callback.Event, c.callback.Stream.ID, got, c.want)
+		}
+	}
+	if want := []string{"start 1_session meeting", "stop arch1", "start 1_session meeting"}; !reflect.DeepEqual(archiver.calls, want) {
+		t.Errorf("archive calls = %v, want %v", archiver.calls, want)
+	}
+	if got := orchestrator.Archive("1_session"); got != "arch2" {
+		t.Errorf("Archive() = %q, want arch2", got)
+	}
+
+	// stopped by the archive duration limit while the hosts are there, a new archive starts
+	result := orchestrator.HandleArchiveCallback(&pkg.ArchiveCallback{
+		Archive: pkg.Archive{Id: "arch2", SessionId: "1_session", Status: pkg.ArchiveStatusStopped},
+		Event:   pkg.EventArchive,
+	})
+	if result == nil || result.Action != ActionStart || orchestrator.Archive("1_session") != "arch3" {
+		t.Errorf("HandleArchiveCallback() = %+v, want arch3 started", result)
+	}
+	// the callback of an archive no longer tracked changes nothing
+	if result := orchestrator.HandleArchiveCallback(&pkg.ArchiveCallback{
+		Archive: pkg.Archive{Id: "arch1", SessionId: "1_session", Status: pkg.ArchiveStatusAvailable},
+		Event:   pkg.EventArchive,
+	}); result != nil || orchestrator.Archive("1_session") != "arch3" {
+		t.Errorf("HandleArchiveCallback() = %+v, want nothing", result)
+	}
+}
+
+func TestOrchestrator_retryStart(t *testing.T) {
+	archiver := &fakeArchiver{startErr: pkg.ErrorServer}
+	orchestrator := NewOrchestrator(archiver, Config{IsHost: isHost})
+
+	if result := orchestrator.HandleStreamCallback(streamCallback(pkg.EventStreamCreated, "host1", "host")); result == nil || result.Err != pkg.ErrorServer {
+		t.Fatalf("HandleStreamCallback() = %+v, want a failed start", result)
+	}
+	if result := orchestrator.Recheck("1_session"); result == nil || result.Err != pkg.ErrorServer {
+		t.Fatalf("Recheck() = %+v, want a failed start", result)
+	}
+	// retried by the next callbacks of the session
+	if result := orchestrator.HandleStreamCallback(streamCallback(pkg.EventStreamCreated, "guest1", "guest")); result == nil || result.Err != pkg.ErrorServer {
+		t.Fatalf("HandleStreamCallback() = %+v, want a failed start", result)
+	}
+	archiver.startErr = nil
+	if result := orchestrator.HandleStreamCallback(streamCallback(pkg.EventStreamDestroyed, "guest1", "guest")); result == nil || result.Action != ActionStart || result.Err != nil {
+		t.Errorf("HandleStreamCallback() = %+v, want started", result)
+	}
+	if got := orchestrator.Archive("1_session"); got != "arch4" {
+		t.Errorf("Archive() = %q, want arch4", got)
+	}
+	if result := orchestrator.Recheck("1_session"); result != nil {
+		t.Errorf("Recheck() = %+v, want nothing while recording", result)
+	}
+	if result := orchestrator.Recheck("1_unknown"); result != nil {
+		t.Errorf("Recheck() = %+v, want nothing for an unknown session", result)
+	}
+}
+
+func TestOrchestrator_minPublishers(t *testing.T) {
+	archiver := &fakeArchiver{stopErr: pkg.ErrorArchiveConflict}
+	orchestrator := NewOrchestrator(archiver, Config{Start: AnyOf(HostPresent(), MinPublishers(3)), IsHost: isHost})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			orchestrator.HandleStreamCallback(streamCallback(pkg.EventStreamCreated, fmt.Sprintf("guest%d", i), "guest"))
+		}(i)
+	}
+	wg.Wait()
+	if archiver.started != 1 {
+		t.Errorf("StartArchive() calls = %d, want 1", archiver.started)
+	}
+
+	// no host to leave, recording stops with the last stream
+	for i := 0; i < 9; i++ {
+		orchestrator.HandleStreamCallback(streamCallback(pkg.EventStreamDestroyed, fmt.Sprintf("guest%d", i), "guest"))
+	}
+	if got := orchestrator.Archive("1_session"); got != "arch1" {
+		t.Errorf("Archive() = %q, want arch1 while a guest publishes", got)
+	}
+	orchestrator.HandleStreamCallback(streamCallback(pkg.EventStreamDestroyed, "guest9", "guest"))
+	if got := orchestrator.Archive("1_session"); got != "" {
+		t.Errorf("Archive() = %q, want none after the archive was already stopped", got)
+	}
+	orchestrator.Forget("1_session")
+	if result := orchestrator.HandleStreamCallback(streamCallback(pkg.EventStreamCreated, "guest0", "guest")); result != nil {
+		t.Errorf("HandleStreamCallback() = %+v, want nothing for a single guest", result)
+	}
+}
+
+func TestOrchestrator_destroyedStream(t *testing.T) {
+	archiver := &fakeArchiver{}
+	orchestrator := NewOrchestrator(archiver, Config{IsHost: isHost})
+
+	orchestrator.HandleStreamCallback(streamCallback(pkg.EventStreamCreated, "host1", "host"))
+	orchestrator.HandleStreamCallback(streamCallback(pkg.EventStreamCreated, "guest1", "guest"))
+	// the streamDestroyed callback is matched to the stream of the streamCreated one
+	result := orchestrator.HandleStreamCallback(streamCallback(pkg.EventStreamDestroyed, "host1", ""))
+	if result == nil || result.Action != ActionStop {
+		t.Errorf("HandleStreamCallback() = %+v, want stop when the last host leaves", result)
+	}
+}
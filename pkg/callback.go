@@ -11,6 +11,7 @@ const (
 	EventConnectionDestroyed  = "connectionDestroyed"
 	EventStreamCreated        = "streamCreated"
 	EventStreamDestroyed      = "streamDestroyed"
+	EventArchive              = "archive"
 	ReasonClientDisconnected  = "clientDisconnected"
 	ReasonForceDisconnected   = "forceDisconnected"
 	ReasonForceUnpublished    = "forceUnpublished"
@@ -50,32 +51,69 @@ type StreamCallback struct {
 	Stream *Stream `json:"stream"`
 }
 
+// The body of an archive status callback, sent every time the status of an archive changes.
+type ArchiveCallback struct {
+	Archive
+	Event string `json:"event"`
+}
+
 type SessionCallback struct {
 	Callback
 	Connection *Connection `json:"connection,omitempty"`
 	Stream     *Stream     `json:"stream,omitempty"`
 }
 
-func (se *SessionCallback) ParseData() (uid string, chatId string, videoCallId string, err error) {
-	var dataString string
-	if connection := se.Connection; connection != nil {
-		dataString = connection.Data
+var ErrorConnectionData = errors.New("invalid connection data, must be the base64 encoded uid&chatId&videoCallId")
+
+// The fields encoded in the data of a connection.
+type ConnectionData struct {
+	Uid         string
+	ChatId      string
+	VideoCallId string
+}
+
+// Decodes connection data, the base64 encoding of "uid&chatId&videoCallId".
+func ParseConnectionData(data string) (*ConnectionData, error) {
+	rawData, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, ErrorConnectionData
+	}
+	dataSplit := strings.Split(string(rawData), "&")
+	if len(dataSplit) < 3 {
+		return nil, ErrorConnectionData
 	}
+	return &ConnectionData{
+		Uid:         dataSplit[0],
+		ChatId:      dataSplit[1],
+		VideoCallId: dataSplit[2],
+	}, nil
+}
+
+// Decodes the data of the connection, see ParseConnectionData.
+func (c *Connection) ParseData() (*ConnectionData, error) {
+	if c == nil || len(c.Data) == 0 {
+		return nil, errors.New("connection data is not present")
+	}
+	return ParseConnectionData(c.Data)
+}
 
+// Decodes the connection data of the callback, the one of its stream when it has one, see
+// ParseConnectionData. The error is ErrorConnectionData when the data is not valid base64 or
+// has fewer than three fields, it used to be nil with empty values, or a panic for short data.
+func (se *SessionCallback) ParseData() (uid string, chatId string, videoCallId string, err error) {
+	connection := se.Connection
 	if stream := se.Stream; stream != nil {
-		dataString = stream.Connection.Data
+		connection = stream.Connection
 	}
 
-	if len(dataString) == 0 {
+	if connection == nil || len(connection.Data) == 0 {
 		err = errors.New("connection/stream is not present")
 		return
 	}
 
-	if rawData, err := base64.StdEncoding.DecodeString(dataString); err == nil {
-		dataSplit := strings.Split(string(rawData), "&")
-		uid = dataSplit[0]
-		chatId = dataSplit[1]
-		videoCallId = dataSplit[2]
+	data, err := connection.ParseData()
+	if err != nil {
+		return
 	}
-	return
+	return data.Uid, data.ChatId, data.VideoCallId, nil
 }
@@ -0,0 +1,62 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseConnectionData(t *testing.T) {
+	type args struct {
+		data string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *ConnectionData
+		wantErr bool
+	}{
+		{"decode", args{"dWlkMSZjaGF0MSZjYWxsMQ=="}, &ConnectionData{"uid1", "chat1", "call1"}, false},
+		{"not_base64", args{"uid1&chat1&call1"}, nil, true},
+		{"missing_fields", args{"dWlkMQ=="}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseConnectionData(tt.args.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseConnectionData() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseConnectionData() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionCallback_ParseData(t *testing.T) {
+	tests := []struct {
+		name     string
+		callback *SessionCallback
+		wantUid  string
+		wantErr  bool
+	}{
+		{"connection", &SessionCallback{Connection: &Connection{Data: "dWlkMSZjaGF0MSZjYWxsMQ=="}}, "uid1", false},
+		{"stream", &SessionCallback{Stream: &Stream{Connection: &Connection{Data: "dWlkMSZjaGF0MSZjYWxsMQ=="}}}, "uid1", false},
+		{"stream_without_connection", &SessionCallback{Stream: &Stream{}}, "", true},
+		{"empty", &SessionCallback{}, "", true},
+		{"invalid_base64", &SessionCallback{Connection: &Connection{Data: "%%%"}}, "", true},
+		{"missing_fields", &SessionCallback{Connection: &Connection{Data: "dWlkMQ=="}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uid, _, _, err := tt.callback.ParseData()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseData() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if uid != tt.wantUid {
+				t.Errorf("ParseData() uid = %v, want %v", uid, tt.wantUid)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Package chat keeps in-call chat history on the server and relays chat messages to the
+// participants of an OpenTok session through signals.
+package chat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/VolodymyrPobochii/opentok-go/pkg"
+)
+
+// The signal type of chat messages, clients listen for "signal:chat".
+const SignalType = "chat"
+
+var (
+	ErrorUnknownType = errors.New("unknown chat message type")
+	ErrorInvalidType = errors.New("invalid chat message type, cannot be empty")
+	ErrorMessageSize = errors.New("chat message too large, the encoded message must fit in the signal data")
+)
+
+// Sends signals, implemented by {@link pkg.OpenTok OpenTok}.
+type Signaler interface {
+	SendSignal(sessionId string, signal pkg.Signal) error
+	SendSignalToConnection(sessionId, connectionId string, signal pkg.Signal) error
+}
+
+// A chat message, sent to the clients as the JSON data of a signal.
+type Message struct {
+	// Unique message ID.
+	Id string `json:"id"`
+	// The message type, registered in the Registry.
+	Type string `json:"type"`
+	// The sender, e.g. a user ID. Optional.
+	From string `json:"from,omitempty"`
+	// The JSON encoded message body.
+	Body json.RawMessage `json:"body"`
+	// When the message was sent, in milliseconds since the UNIX epoch.
+	SentAt int64 `json:"sentAt"`
+	// Set on messages sent again by Replay.
+	Replay bool `json:"replay,omitempty"`
+}
+
+// Relays chat messages through OpenTok signals and keeps their history.
+type Chat struct {
+	signaler Signaler
+	registry *Registry
+	store    Store
+}
+
+// Creates a Chat.
+//
+// @param signaler Usually the {@link pkg.OpenTok OpenTok} instance.
+// @param registry The message types that can be sent.
+// @param store Where the history is kept, e.g. NewMemoryStore(100).
+func New(signaler Signaler, registry *Registry, store Store) *Chat {
+	return &Chat{signaler: signaler, registry: registry, store: store}
+}
+
+// Sends a chat message to all the clients connected to the session and appends it to the
+// history of the session.
+//
+// @param sessionId The session ID of the OpenTok session.
+// @param from The sender, e.g. a user ID. Optional.
+// @param messageType A type registered in the Registry.
+// @param body The message body, it must decode into the registered type.
+//
+// @return The message sent.
+func (c *Chat) Send(sessionId, from, messageType string, body interface{}) (*Message, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	message := &Message{
+		Id:     newMessageId(),
+		Type:   messageType,
+		From:   from,
+		Body:   data,
+		SentAt: time.Now().UnixNano() / int64(time.Millisecond),
+	}
+	if _, err := c.registry.Decode(message); err != nil {
+		return nil, err
+	}
+	signal, err := newSignal(message)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.signaler.SendSignal(sessionId, signal); err != nil {
+		return nil, err
+	}
+	if err := c.store.Append(sessionId, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// Returns the chat history of the session, oldest message first.
+func (c *Chat) History(sessionId string) ([]*Message, error) {
+	return c.store.History(sessionId)
+}
+
+// Sends the chat history of the session to one client, e.g. a late joiner. The messages are
+// sent in order with Replay set.
+//
+// @param sessionId The session ID of the OpenTok session.
+// @param connectionId The connection ID of the client.
+func (c *Chat) Replay(sessionId, connectionId string) error {
+	history, err := c.store.History(sessionId)
+	if err != nil {
+		return err
+	}
+	for _, message := range history {
+		replay := *message
+		replay.Replay = true
+		signal, err := newSignal(&replay)
+		if err != nil {
+			return err
+		}
+		if err := c.signaler.SendSignalToConnection(sessionId, connectionId, signal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Replays the chat history to the client of a connectionCreated webhook, other callbacks are
+// ignored.
+func (c *Chat) HandleConnectionCallback(callback *pkg.ConnectionCallback) error {
+	if callback == nil || callback.Event != pkg.EventConnectionCreated || callback.Connection == nil {
+		return nil
+	}
+	return c.Replay(callback.SessionID, callback.Connection.ID)
+}
+
+func newSignal(message *Message) (pkg.Signal, error) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return pkg.Signal{}, err
+	}
+	if len(data) > pkg.SignalDataMaxSize {
+		return pkg.Signal{}, ErrorMessageSize
+	}
+	return pkg.Signal{Type: SignalType, Data: string(data)}, nil
+}
+
+func newMessageId() string {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,132 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/VolodymyrPobochii/opentok-go/pkg"
+)
+
+type sentSignal struct {
+	sessionId    string
+	connectionId string
+	message      *Message
+}
+
+type fakeSignaler struct {
+	sent []sentSignal
+	err  error
+}
+
+func (f *fakeSignaler) record(sessionId, connectionId string, signal pkg.Signal) error {
+	if f.err != nil {
+		return f.err
+	}
+	if signal.Type != SignalType {
+		return errors.New("unexpected signal type " + signal.Type)
+	}
+	var message *Message
+	if err := json.Unmarshal([]byte(signal.Data), &message); err != nil {
+		return err
+	}
+	f.sent = append(f.sent, sentSignal{sessionId, connectionId, message})
+	return nil
+}
+
+func (f *fakeSignaler) SendSignal(sessionId string, signal pkg.Signal) error {
+	return f.record(sessionId, "", signal)
+}
+
+func (f *fakeSignaler) SendSignalToConnection(sessionId, connectionId string, signal pkg.Signal) error {
+	return f.record(sessionId, connectionId, signal)
+}
+
+type textMessage struct {
+	Text string `json:"text"`
+}
+
+func newTestChat(t *testing.T, limit int) (*Chat, *fakeSignaler) {
+	t.Helper()
+	registry := NewRegistry()
+	if err := registry.Register("text", func() interface{} { return &textMessage{} }); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	signaler := &fakeSignaler{}
+	return New(signaler, registry, NewMemoryStore(limit)), signaler
+}
+
+func TestChat_Send(t *testing.T) {
+	tests := []struct {
+		name        string
+		messageType string
+		body        interface{}
+		signalErr   error
+		wantErr     error
+		wantHistory int
+	}{
+		{"text", "text", &textMessage{"hello"}, nil, nil, 1},
+		{"unknown_type", "poll", &textMessage{"hello"}, nil, ErrorUnknownType, 0},
+		{"too_large", "text", &textMessage{strings.Repeat("a", pkg.SignalDataMaxSize)}, nil, ErrorMessageSize, 0},
+		{"signal_failed", "text", &textMessage{"hello"}, pkg.ErrorSessionNotFound, pkg.ErrorSessionNotFound, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat, signaler := newTestChat(t, 10)
+			signaler.err = tt.signalErr
+			message, err := chat.Send("1_session", "user1", tt.messageType, tt.body)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			history, _ := chat.History("1_session")
+			if len(history) != tt.wantHistory {
+				t.Fatalf("History() = %d messages, want %d", len(history), tt.wantHistory)
+			}
+			if err != nil {
+				return
+			}
+			if len(signaler.sent) != 1 || !reflect.DeepEqual(signaler.sent[0].message, message) {
+				t.Errorf("Send() signals = %+v, want %+v", signaler.sent, message)
+			}
+			decoded, err := chat.registry.Decode(history[0])
+			if err != nil || !reflect.DeepEqual(decoded, tt.body) {
+				t.Errorf("Decode() = %+v, %v, want %+v", decoded, err, tt.body)
+			}
+		})
+	}
+}
+
+func TestChat_HandleConnectionCallback(t *testing.T) {
+	chat, signaler := newTestChat(t, 2)
+	for _, text := range []string{"one", "two", "three"} {
+		if _, err := chat.Send("1_session", "user1", "text", &textMessage{text}); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	signaler.sent = nil
+
+	callbacks := []*pkg.ConnectionCallback{
+		{Callback: pkg.Callback{SessionID: "1_session", Event: pkg.EventConnectionDestroyed}, Connection: &pkg.Connection{ID: "conn1"}},
+		{Callback: pkg.Callback{SessionID: "1_session", Event: pkg.EventConnectionCreated}, Connection: &pkg.Connection{ID: "conn2"}},
+	}
+	for _, callback := range callbacks {
+		if err := chat.HandleConnectionCallback(callback); err != nil {
+			t.Fatalf("HandleConnectionCallback() error = %v", err)
+		}
+	}
+
+	var got []string
+	for _, sent := range signaler.sent {
+		var body textMessage
+		_ = json.Unmarshal(sent.message.Body, &body)
+		if sent.connectionId != "conn2" || !sent.message.Replay {
+			t.Errorf("Replay() signal = %+v", sent)
+		}
+		got = append(got, body.Text)
+	}
+	if want := []string{"two", "three"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Replay() = %v, want %v", got, want)
+	}
+}
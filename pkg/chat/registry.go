@@ -0,0 +1,52 @@
+package chat
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// The chat message types that can be sent, with a factory of the Go value each body decodes
+// into.
+type Registry struct {
+	mu    sync.RWMutex
+	types map[string]func() interface{}
+}
+
+func NewRegistry() *Registry {
+	return &Registry{types: make(map[string]func() interface{})}
+}
+
+// Registers a message type. The factory returns a pointer to a new value the message body is
+// decoded into, e.g. func() interface{} { return &TextMessage{} }.
+func (r *Registry) Register(messageType string, factory func() interface{}) error {
+	if len(messageType) == 0 || factory == nil {
+		return ErrorInvalidType
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.types[messageType] = factory
+	return nil
+}
+
+// Whether the message type is registered.
+func (r *Registry) Registered(messageType string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.types[messageType]
+	return ok
+}
+
+// Decodes the body of a message into the value of its registered type.
+func (r *Registry) Decode(message *Message) (interface{}, error) {
+	r.mu.RLock()
+	factory, ok := r.types[message.Type]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, ErrorUnknownType
+	}
+	value := factory()
+	if err := json.Unmarshal(message.Body, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
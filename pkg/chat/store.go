@@ -0,0 +1,50 @@
+package chat
+
+import "sync"
+
+// Keeps the chat history of sessions.
+type Store interface {
+	// Appends a message to the history of the session.
+	Append(sessionId string, message *Message) error
+	// Returns the history of the session, oldest message first.
+	History(sessionId string) ([]*Message, error)
+}
+
+// A Store keeping the last messages of every session in memory.
+type MemoryStore struct {
+	limit    int
+	mu       sync.RWMutex
+	sessions map[string][]*Message
+}
+
+// Creates a MemoryStore keeping at most limit messages per session (default 100).
+func NewMemoryStore(limit int) *MemoryStore {
+	if limit <= 0 {
+		limit = 100
+	}
+	return &MemoryStore{limit: limit, sessions: make(map[string][]*Message)}
+}
+
+func (s *MemoryStore) Append(sessionId string, message *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	history := append(s.sessions[sessionId], message)
+	if len(history) > s.limit {
+		history = append([]*Message(nil), history[len(history)-s.limit:]...)
+	}
+	s.sessions[sessionId] = history
+	return nil
+}
+
+func (s *MemoryStore) History(sessionId string) ([]*Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]*Message(nil), s.sessions[sessionId]...), nil
+}
+
+// Drops the history of a session, e.g. once the session ended.
+func (s *MemoryStore) Delete(sessionId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionId)
+}
@@ -5,16 +5,43 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"time"
 )
 
+var (
+	ErrorMissingSessionId   = errors.New("a sessionId parameter is required")
+	ErrorMissingStreamId    = errors.New("a streamId parameter is required")
+	ErrorInvalidRequest     = errors.New("invalid request")
+	ErrorAuthentication     = errors.New("an authentication error occurred")
+	ErrorServer             = errors.New("a server error occurred")
+	ErrorUnexpected         = errors.New("unexpected response from the OpenTok API")
+	ErrorRequestFailed      = errors.New("the request failed")
+	ErrorSessionNotFound    = errors.New("the session was not found")
+	ErrorStreamNotFound     = errors.New("the stream was not found")
+	ErrorConnectionNotFound = errors.New("the connection was not found")
+)
+
 type Endpoints struct {
 	CreateSession       string
 	GetStream           string
 	ListStreams         string
 	SetArchiveLayout    string
 	SetStreamClassLists string
+	Signal              string
+	SignalConnection    string
+	ForceDisconnect     string
+	ForceMuteStream     string
+	ForceMuteAll        string
+	StartArchive        string
+	StopArchive         string
+	GetArchive          string
+	ListArchives        string
+	DeleteArchive       string
+	ArchiveStreams      string
+	ArchiveStorage      string
 	Dial                string
 	StartBroadcast      string
 	StopBroadcast       string
@@ -63,16 +90,28 @@ func defaultConfig() *Config {
 		ApiUrl:    "https://api.opentok.com",
 		Endpoints: &Endpoints{
 			CreateSession:       "/session/create",
-			GetStream:           "/v2/project/%s/session/%s/stream/%s", //<%apiKey%>,<%sessionId%>,<%streamId%>
-			ListStreams:         "/v2/project/%s/session/%s/stream",    //<%apiKey%>,<%sessionId%>
-			SetArchiveLayout:    "/v2/project/%s/archive/%s/layout",    //<%apiKey%>,<%archiveId%>
-			SetStreamClassLists: "/v2/project/%s/session/%s/stream",    //<%apiKey%>,<%sessionId%>
-			Dial:                "/v2/project/%s/dial",                 //<%apiKey%>
-			StartBroadcast:      "/v2/project/%s/broadcast",            //<%apiKey%>
-			StopBroadcast:       "/v2/project/%s/broadcast/%s/stop",    //<%apiKey%>,<%broadcastId%>
-			GetBroadcast:        "/v2/project/%s/broadcast/%s",         //<%apiKey%>,<%broadcastId%>
-			SetBroadcastLayout:  "/v2/project/%s/broadcast/%s/layout",  //<%apiKey%>,<%broadcastId%>
-			ListBroadcasts:      "/v2/project/%s/broadcast",            //<%apiKey%>
+			GetStream:           "/v2/project/%s/session/%s/stream/%s",            //<%apiKey%>,<%sessionId%>,<%streamId%>
+			ListStreams:         "/v2/project/%s/session/%s/stream",               //<%apiKey%>,<%sessionId%>
+			SetArchiveLayout:    "/v2/project/%s/archive/%s/layout",               //<%apiKey%>,<%archiveId%>
+			SetStreamClassLists: "/v2/project/%s/session/%s/stream",               //<%apiKey%>,<%sessionId%>
+			Signal:              "/v2/project/%s/session/%s/signal",               //<%apiKey%>,<%sessionId%>
+			SignalConnection:    "/v2/project/%s/session/%s/connection/%s/signal", //<%apiKey%>,<%sessionId%>,<%connectionId%>
+			ForceDisconnect:     "/v2/project/%s/session/%s/connection/%s",        //<%apiKey%>,<%sessionId%>,<%connectionId%>
+			ForceMuteStream:     "/v2/project/%s/session/%s/stream/%s/mute",       //<%apiKey%>,<%sessionId%>,<%streamId%>
+			ForceMuteAll:        "/v2/project/%s/session/%s/mute",                 //<%apiKey%>,<%sessionId%>
+			StartArchive:        "/v2/project/%s/archive",                         //<%apiKey%>
+			StopArchive:         "/v2/project/%s/archive/%s/stop",                 //<%apiKey%>,<%archiveId%>
+			GetArchive:          "/v2/project/%s/archive/%s",                      //<%apiKey%>,<%archiveId%>
+			ListArchives:        "/v2/project/%s/archive",                         //<%apiKey%>
+			DeleteArchive:       "/v2/project/%s/archive/%s",                      //<%apiKey%>,<%archiveId%>
+			ArchiveStreams:      "/v2/project/%s/archive/%s/streams",              //<%apiKey%>,<%archiveId%>
+			ArchiveStorage:      "/v2/project/%s/archive/storage",                 //<%apiKey%>
+			Dial:                "/v2/project/%s/dial",                            //<%apiKey%>
+			StartBroadcast:      "/v2/project/%s/broadcast",                       //<%apiKey%>
+			StopBroadcast:       "/v2/project/%s/broadcast/%s/stop",               //<%apiKey%>,<%broadcastId%>
+			GetBroadcast:        "/v2/project/%s/broadcast/%s",                    //<%apiKey%>,<%broadcastId%>
+			SetBroadcastLayout:  "/v2/project/%s/broadcast/%s/layout",             //<%apiKey%>,<%broadcastId%>
+			ListBroadcasts:      "/v2/project/%s/broadcast",                       //<%apiKey%>
 		},
 		Request: &Request{Timeout: 20000}, // 20 seconds
 		Auth:    &Auth{Expire: 300},
@@ -109,25 +148,25 @@ type CreateSessionError struct {
 	Message string `json:"message"`
 }
 
-func (c *Client) createSession(options map[string]interface{}) (string, error) {
+func (c *Client) createSession(options map[string]interface{}) (*CreateSessionResponse, error) {
 	body, err := json.Marshal(options)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	url := fmt.Sprintf("%v%v", c.config.ApiUrl, c.config.Endpoints.CreateSession)
 	request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(body))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	err = c.generateHeaders(&request.Header)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	response, err := http.DefaultClient.Do(request)
 	if err != nil {
-		return "", errors.New(fmt.Sprintf("the request failed: %v", err))
+		return nil, &TransportError{Err: err}
 	}
 
 	defer response.Body.Close()
@@ -138,22 +177,130 @@ func (c *Client) createSession(options map[string]interface{}) (string, error) {
 	// handle client errors
 	if response.StatusCode == 403 {
 		_ = decoder.Decode(&responseError)
-		return "", errors.New(fmt.Sprintf("an authentication error occurred: (%d) %v", response.StatusCode, responseError))
+		return nil, errors.New(fmt.Sprintf("an authentication error occurred: (%d) %v", response.StatusCode, responseError))
 	}
 
 	// handle server errors
 	if response.StatusCode >= 500 && response.StatusCode <= 599 {
 		_ = decoder.Decode(&responseError)
-		return "", errors.New(fmt.Sprintf("a server error occurred: (%d) %v", response.StatusCode, responseError))
+		return nil, errors.New(fmt.Sprintf("a server error occurred: (%d) %v", response.StatusCode, responseError))
 	}
 
 	var sessionResponse []*CreateSessionResponse
 	err = decoder.Decode(&sessionResponse)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if len(sessionResponse) == 0 || sessionResponse[0] == nil {
+		return nil, errors.New("the create session response contained no sessions")
+	}
+
+	return sessionResponse[0], nil
+}
+
+// Returned when an OpenTok REST API call responds with an error status. Err is one of the
+// Error* values, so the cause can be checked with errors.Is.
+type RequestError struct {
+	StatusCode int    `json:"-"`
+	Code       int    `json:"code"`
+	Message    string `json:"message"`
+	Err        error  `json:"-"`
+}
+
+func (e *RequestError) Error() string {
+	if len(e.Message) == 0 {
+		return fmt.Sprintf("%v: (%d)", e.Err, e.StatusCode)
+	}
+	return fmt.Sprintf("%v: (%d) %s", e.Err, e.StatusCode, e.Message)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// Returned when a request to the OpenTok REST API got no response, e.g. on a network error or
+// a timeout. It matches ErrorRequestFailed with errors.Is and Err is the error of the HTTP client.
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrorRequestFailed, e.Err)
+}
+
+func (e *TransportError) Is(target error) bool {
+	return target == ErrorRequestFailed
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// builds the url of a REST endpoint, the format receives the api key followed by the
+// path escaped params
+func (c *Client) endpointUrl(endpoint string, params ...string) string {
+	args := []interface{}{c.config.ApiKey}
+	for _, param := range params {
+		args = append(args, url.PathEscape(param))
+	}
+	return fmt.Sprintf("%v%v", c.config.ApiUrl, fmt.Sprintf(endpoint, args...))
+}
+
+// Sends a JSON request to the OpenTok REST API and decodes the JSON response into result.
+// statusErrors maps operation specific response statuses to Error* values, the remaining
+// client and server errors map to ErrorInvalidRequest, ErrorAuthentication and ErrorServer.
+// A request that got no response fails with a TransportError.
+func (c *Client) request(method, requestUrl string, body interface{}, result interface{}, statusErrors map[int]error) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewBuffer(data)
+	}
+	request, err := http.NewRequest(method, requestUrl, reader)
+	if err != nil {
+		return err
+	}
+
+	err = c.generateHeaders(&request.Header)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
 	}
 
-	return sessionResponse[0].SessionId, nil
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return &TransportError{Err: err}
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		if result == nil || response.StatusCode == http.StatusNoContent {
+			return nil
+		}
+		return json.NewDecoder(response.Body).Decode(result)
+	}
+
+	requestError := &RequestError{StatusCode: response.StatusCode}
+	_ = json.NewDecoder(response.Body).Decode(requestError)
+	switch {
+	case statusErrors[response.StatusCode] != nil:
+		requestError.Err = statusErrors[response.StatusCode]
+	case response.StatusCode == http.StatusForbidden:
+		requestError.Err = ErrorAuthentication
+	case response.StatusCode >= 500 && response.StatusCode <= 599:
+		requestError.Err = ErrorServer
+	case response.StatusCode >= 400 && response.StatusCode <= 499:
+		requestError.Err = ErrorInvalidRequest
+	default:
+		requestError.Err = ErrorUnexpected
+	}
+	return requestError
 }
 
 func (c *Client) generateHeaders(header *http.Header) error {
@@ -167,9 +314,14 @@ func (c *Client) generateHeaders(header *http.Header) error {
 	return nil
 }
 
+// merges the ClientConfig built by NewOpenTok, a non-empty ApiUrl replaces the default
+// https://api.opentok.com for every REST request
 func (c *Client) configure(clientConfig *ClientConfig) *Config {
 	// merge configs
 	c.config.ClientConfig = *clientConfig
+	if len(clientConfig.ApiUrl) != 0 {
+		c.config.ApiUrl = clientConfig.ApiUrl
+	}
 	if len(c.config.Endpoints.Dial) != 0 && len(c.config.ApiKey) != 0 {
 		c.config.Endpoints.Dial = fmt.Sprintf(c.config.Endpoints.Dial, c.config.ApiKey)
 	}
@@ -0,0 +1,94 @@
+package pkg
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	testApiKey    = "46513602"
+	testApiSecret = "d06eaf53e214c105f02f2615170a04e08bf39aa6"
+)
+
+// starts a local stand-in for the OpenTok REST API and an OpenTok instance talking to it
+func newTestOpenTok(t *testing.T, handler http.HandlerFunc) *OpenTok {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.Header.Get("X-OPENTOK-AUTH")) == 0 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return NewOpenTok(testApiKey, testApiSecret, server.URL)
+}
+
+func TestClient_request(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		statusErrors map[int]error
+		wantErr      error
+		wantMessage  string
+	}{
+		{"ok", http.StatusOK, `{}`, nil, nil, ""},
+		{"no_content", http.StatusNoContent, ``, nil, nil, ""},
+		{"bad_request", http.StatusBadRequest, `{"code":400,"message":"bad"}`, nil, ErrorInvalidRequest, "bad"},
+		{"forbidden", http.StatusForbidden, `{"code":403,"message":"nope"}`, nil, ErrorAuthentication, "nope"},
+		{"server", http.StatusBadGateway, ``, nil, ErrorServer, ""},
+		{"mapped", http.StatusNotFound, `{"message":"gone"}`, map[int]error{http.StatusNotFound: ErrorSessionNotFound}, ErrorSessionNotFound, "gone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ot := newTestOpenTok(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+			var result map[string]interface{}
+			err := ot.client.request(http.MethodGet, ot.client.endpointUrl("/v2/project/%s"), nil, &result, tt.statusErrors)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("request() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var requestError *RequestError
+			if errors.As(err, &requestError) && (requestError.StatusCode != tt.status || requestError.Message != tt.wantMessage) {
+				t.Errorf("request() error = %+v, want status %d message %q", requestError, tt.status, tt.wantMessage)
+			}
+		})
+	}
+
+	t.Run("no_response", func(t *testing.T) {
+		ot := newTestOpenTok(t, func(w http.ResponseWriter, r *http.Request) {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+		})
+		err := ot.client.request(http.MethodGet, ot.client.endpointUrl("/v2/project/%s"), nil, nil, nil)
+		var transportError *TransportError
+		if !errors.Is(err, ErrorRequestFailed) || !errors.As(err, &transportError) || transportError.Err == nil {
+			t.Errorf("request() error = %v, want %v", err, ErrorRequestFailed)
+		}
+	})
+}
+
+func TestClient_configure(t *testing.T) {
+	tests := []struct {
+		name string
+		env  interface{}
+		want string
+	}{
+		{"default", nil, "https://api.opentok.com"},
+		{"string", "http://localhost:8080", "http://localhost:8080"},
+		{"options", map[string]interface{}{"apiUrl": "https://api.example.com"}, "https://api.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ot := NewOpenTok(testApiKey, testApiSecret, tt.env)
+			if got, want := ot.client.endpointUrl("/v2/project/%s"), tt.want+"/v2/project/"+testApiKey; got != want {
+				t.Errorf("endpointUrl() = %v, want %v", got, want)
+			}
+		})
+	}
+}
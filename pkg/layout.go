@@ -0,0 +1,79 @@
+package pkg
+
+import (
+	"errors"
+	"net/http"
+)
+
+// A predefined layout of a composed archive, or LayoutCustom.
+type LayoutType string
+
+const (
+	LayoutBestFit                LayoutType = "bestFit"
+	LayoutPip                    LayoutType = "pip"
+	LayoutVerticalPresentation   LayoutType = "verticalPresentation"
+	LayoutHorizontalPresentation LayoutType = "horizontalPresentation"
+	LayoutCustom                 LayoutType = "custom"
+)
+
+var (
+	ErrorLayoutType            = errors.New("invalid layout type, must be bestFit, pip, verticalPresentation, horizontalPresentation or custom")
+	ErrorLayoutStyleSheet      = errors.New("invalid layout, a stylesheet is required by the custom layout and not allowed by the others")
+	ErrorLayoutScreenshareType = errors.New("invalid layout, screenshareType is only allowed with bestFit and must be a predefined layout")
+)
+
+// The layout of the streams in a composed archive, see
+// <a href="https://tokbox.com/developer/guides/archiving/layout-control.html">layout control</a>.
+type Layout struct {
+	Type LayoutType `json:"type"`
+	// The CSS of a custom layout.
+	StyleSheet string `json:"stylesheet,omitempty"`
+	// The layout used while a screen is shared, only with the bestFit layout. Optional.
+	ScreenshareType LayoutType `json:"screenshareType,omitempty"`
+}
+
+func (t LayoutType) predefined() bool {
+	switch t {
+	case LayoutBestFit, LayoutPip, LayoutVerticalPresentation, LayoutHorizontalPresentation:
+		return true
+	}
+	return false
+}
+
+func (l *Layout) validate() error {
+	if !l.Type.predefined() && l.Type != LayoutCustom {
+		return ErrorLayoutType
+	}
+	if (l.Type == LayoutCustom) != (len(l.StyleSheet) != 0) {
+		return ErrorLayoutStyleSheet
+	}
+	if len(l.ScreenshareType) != 0 && (l.Type != LayoutBestFit || !l.ScreenshareType.predefined()) {
+		return ErrorLayoutScreenshareType
+	}
+	return nil
+}
+
+func (c *Client) setArchiveLayout(archiveId string, layout *Layout) error {
+	return c.request(http.MethodPut, c.endpointUrl(c.config.Endpoints.SetArchiveLayout, archiveId), layout, nil,
+		map[int]error{
+			http.StatusNotFound: ErrorArchiveNotFound,
+			http.StatusConflict: ErrorArchiveConflict,
+		})
+}
+
+// Changes the layout of a composed archive while it is being recorded.
+//
+// @param archiveId The archive ID.
+// @param layout The new layout. A stylesheet is required by the custom layout and rejected by
+// the predefined ones, a screenshareType is only allowed with bestFit.
+//
+// @return The error wraps ErrorArchiveNotFound when the archive does not exist.
+func (ot *OpenTok) SetArchiveLayout(archiveId string, layout Layout) error {
+	if len(archiveId) == 0 {
+		return ErrorMissingArchiveId
+	}
+	if err := layout.validate(); err != nil {
+		return err
+	}
+	return ot.client.setArchiveLayout(archiveId, &layout)
+}
@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestLayout_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  Layout
+		wantErr error
+	}{
+		{"best_fit", Layout{Type: LayoutBestFit}, nil},
+		{"best_fit_screenshare", Layout{Type: LayoutBestFit, ScreenshareType: LayoutHorizontalPresentation}, nil},
+		{"pip", Layout{Type: LayoutPip}, nil},
+		{"custom", Layout{Type: LayoutCustom, StyleSheet: "stream.instructor {position: absolute;}"}, nil},
+		{"empty", Layout{}, ErrorLayoutType},
+		{"unknown", Layout{Type: "grid"}, ErrorLayoutType},
+		{"custom_without_stylesheet", Layout{Type: LayoutCustom}, ErrorLayoutStyleSheet},
+		{"stylesheet_on_predefined", Layout{Type: LayoutPip, StyleSheet: "stream {}"}, ErrorLayoutStyleSheet},
+		{"screenshare_on_pip", Layout{Type: LayoutPip, ScreenshareType: LayoutBestFit}, ErrorLayoutScreenshareType},
+		{"screenshare_custom", Layout{Type: LayoutBestFit, ScreenshareType: LayoutCustom}, ErrorLayoutScreenshareType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.layout.validate(); err != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenTok_SetArchiveLayout(t *testing.T) {
+	var gotBody map[string]interface{}
+	ot := newTestOpenTok(t, func(w http.ResponseWriter, r *http.Request) {
+		gotBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		if r.Method != http.MethodPut || r.URL.Path != "/v2/project/"+testApiKey+"/archive/arch1/layout" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	tests := []struct {
+		name      string
+		archiveId string
+		layout    Layout
+		wantBody  map[string]interface{}
+		wantErr   error
+	}{
+		{
+			"best_fit",
+			"arch1",
+			Layout{Type: LayoutBestFit, ScreenshareType: LayoutPip},
+			map[string]interface{}{"type": "bestFit", "screenshareType": "pip"},
+			nil,
+		},
+		{"unknown_archive", "arch2", Layout{Type: LayoutPip}, map[string]interface{}{"type": "pip"}, ErrorArchiveNotFound},
+		{"invalid", "arch1", Layout{Type: LayoutCustom}, nil, ErrorLayoutStyleSheet},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotBody = nil
+			if err := ot.SetArchiveLayout(tt.archiveId, tt.layout); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetArchiveLayout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(gotBody, tt.wantBody) {
+				t.Errorf("SetArchiveLayout() request body = %v, want %v", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package pkg
+
+import "net/http"
+
+func (c *Client) forceDisconnect(sessionId, connectionId string) error {
+	return c.request(http.MethodDelete, c.endpointUrl(c.config.Endpoints.ForceDisconnect, sessionId, connectionId), nil, nil,
+		map[int]error{
+			http.StatusNotFound: ErrorConnectionNotFound,
+		})
+}
+
+// Disconnects a client from an OpenTok session, as a moderator client would with
+// <code>forceDisconnect()</code>.
+//
+// @param sessionId The session ID of the OpenTok session.
+// @param connectionId The connection ID of the client.
+//
+// @return The error wraps ErrorConnectionNotFound when the client is not connected to the
+// session.
+func (ot *OpenTok) ForceDisconnect(sessionId, connectionId string) error {
+	if len(sessionId) == 0 {
+		return ErrorMissingSessionId
+	}
+	if len(connectionId) == 0 {
+		return ErrorMissingConnectionId
+	}
+	return ot.client.forceDisconnect(sessionId, connectionId)
+}
+
+// Disconnects a client from this session.
+// See {@link OpenTok#ForceDisconnect OpenTok.ForceDisconnect()}.
+func (s *Session) ForceDisconnect(connectionId string) error {
+	if s.ot == nil {
+		return ErrorSessionNotBound
+	}
+	return s.ot.ForceDisconnect(s.sessionId, connectionId)
+}
+
+// the body of the session-wide mute request, the response carries no mute state
+type forceMuteRequest struct {
+	Active            bool     `json:"active"`
+	ExcludedStreamIds []string `json:"excludedStreamIds,omitempty"`
+}
+
+func (c *Client) forceMuteStream(sessionId, streamId string) error {
+	return c.request(http.MethodPost, c.endpointUrl(c.config.Endpoints.ForceMuteStream, sessionId, streamId), nil, nil,
+		map[int]error{
+			http.StatusNotFound: ErrorStreamNotFound,
+		})
+}
+
+func (c *Client) forceMuteAll(sessionId string, request *forceMuteRequest) error {
+	return c.request(http.MethodPost, c.endpointUrl(c.config.Endpoints.ForceMuteAll, sessionId), request, nil,
+		map[int]error{
+			http.StatusNotFound: ErrorSessionNotFound,
+		})
+}
+
+// Mutes the audio of a stream published in an OpenTok session. The client can unmute the
+// stream again.
+//
+// @param sessionId The session ID of the OpenTok session.
+// @param streamId The stream ID.
+//
+// @return The error wraps ErrorStreamNotFound when the stream is not published in the session.
+func (ot *OpenTok) ForceMuteStream(sessionId, streamId string) error {
+	if len(sessionId) == 0 {
+		return ErrorMissingSessionId
+	}
+	if len(streamId) == 0 {
+		return ErrorMissingStreamId
+	}
+	return ot.client.forceMuteStream(sessionId, streamId)
+}
+
+// Mutes the audio of all the streams published in an OpenTok session, except for the excluded
+// ones. Streams published later are muted too, until DisableForceMute is called.
+//
+// @param sessionId The session ID of the OpenTok session.
+// @param excludedStreamIds The streams to leave unmuted, e.g. the presenter.
+//
+// @return The error wraps ErrorSessionNotFound when the session does not exist.
+func (ot *OpenTok) ForceMuteAll(sessionId string, excludedStreamIds []string) error {
+	if len(sessionId) == 0 {
+		return ErrorMissingSessionId
+	}
+	for _, streamId := range excludedStreamIds {
+		if len(streamId) == 0 {
+			return ErrorMissingStreamId
+		}
+	}
+	return ot.client.forceMuteAll(sessionId, &forceMuteRequest{Active: true, ExcludedStreamIds: excludedStreamIds})
+}
+
+// Turns off the session-wide mute set with ForceMuteAll, streams published later are no longer
+// muted. Streams muted already stay muted until their clients unmute them.
+//
+// @param sessionId The session ID of the OpenTok session.
+//
+// @return The error wraps ErrorSessionNotFound when the session does not exist.
+func (ot *OpenTok) DisableForceMute(sessionId string) error {
+	if len(sessionId) == 0 {
+		return ErrorMissingSessionId
+	}
+	return ot.client.forceMuteAll(sessionId, &forceMuteRequest{Active: false})
+}
+
+// Mutes the audio of a stream published in this session.
+// See {@link OpenTok#ForceMuteStream OpenTok.ForceMuteStream()}.
+func (s *Session) ForceMuteStream(streamId string) error {
+	if s.ot == nil {
+		return ErrorSessionNotBound
+	}
+	return s.ot.ForceMuteStream(s.sessionId, streamId)
+}
+
+// Mutes the audio of all the streams published in this session, except for the excluded ones.
+// See {@link OpenTok#ForceMuteAll OpenTok.ForceMuteAll()}.
+func (s *Session) ForceMuteAll(excludedStreamIds []string) error {
+	if s.ot == nil {
+		return ErrorSessionNotBound
+	}
+	return s.ot.ForceMuteAll(s.sessionId, excludedStreamIds)
+}
+
+// Turns off the session-wide mute of this session.
+// See {@link OpenTok#DisableForceMute OpenTok.DisableForceMute()}.
+func (s *Session) DisableForceMute() error {
+	if s.ot == nil {
+		return ErrorSessionNotBound
+	}
+	return s.ot.DisableForceMute(s.sessionId)
+}
@@ -0,0 +1,59 @@
+package moderation
+
+import (
+	"sync"
+	"time"
+)
+
+// A decision of the rules engine.
+type AuditEntry struct {
+	Time      time.Time
+	SessionId string
+	// The callback event that triggered the decision.
+	Event  string
+	Rule   string
+	Action Action
+	// The connection or stream acted on.
+	Target string
+	Reason string
+	// Set when the engine runs in dry-run mode and did not act.
+	DryRun bool
+	// The error of the force-disconnect or force-mute call.
+	Err error
+}
+
+// Records the decisions of the rules engine.
+type AuditLog interface {
+	Record(entry *AuditEntry)
+}
+
+// An AuditLog keeping the last entries in memory.
+type MemoryAuditLog struct {
+	limit   int
+	mu      sync.Mutex
+	entries []*AuditEntry
+}
+
+// Creates a MemoryAuditLog keeping at most limit entries (default 1000).
+func NewMemoryAuditLog(limit int) *MemoryAuditLog {
+	if limit <= 0 {
+		limit = 1000
+	}
+	return &MemoryAuditLog{limit: limit}
+}
+
+func (l *MemoryAuditLog) Record(entry *AuditEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+	if len(l.entries) > l.limit {
+		l.entries = append([]*AuditEntry(nil), l.entries[len(l.entries)-l.limit:]...)
+	}
+}
+
+// Returns the recorded entries, oldest first.
+func (l *MemoryAuditLog) Entries() []*AuditEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*AuditEntry(nil), l.entries...)
+}
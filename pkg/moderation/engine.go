@@ -0,0 +1,174 @@
+// Package moderation enforces declarative rules on OpenTok sessions. The rules engine consumes
+// the connection and stream callbacks of the sessions and force-disconnects or force-mutes the
+// clients that violate a rule.
+package moderation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/VolodymyrPobochii/opentok-go/pkg"
+)
+
+// Acts on rule violations, implemented by {@link pkg.OpenTok OpenTok}.
+type Moderator interface {
+	ForceDisconnect(sessionId, connectionId string) error
+	ForceMuteStream(sessionId, streamId string) error
+}
+
+// The connections and streams of a session, as known from its callbacks.
+type SessionState struct {
+	Connections map[string]*pkg.Connection
+	Streams     map[string]*pkg.Stream
+}
+
+func newSessionState() *SessionState {
+	return &SessionState{
+		Connections: make(map[string]*pkg.Connection),
+		Streams:     make(map[string]*pkg.Stream),
+	}
+}
+
+// The connections whose data decodes to the user ID.
+func (s *SessionState) UserConnections(uid string) []*pkg.Connection {
+	var connections []*pkg.Connection
+	for _, connection := range s.Connections {
+		if data, err := connection.ParseData(); err == nil && data.Uid == uid {
+			connections = append(connections, connection)
+		}
+	}
+	return connections
+}
+
+type Config struct {
+	// Evaluate the rules and audit the decisions without acting on them.
+	DryRun bool
+	// Where decisions are recorded (default a MemoryAuditLog).
+	Audit AuditLog
+}
+
+// Evaluates rules against the connection and stream callbacks of sessions.
+type Engine struct {
+	moderator Moderator
+	rules     []Rule
+	dryRun    bool
+	audit     AuditLog
+	mu        sync.Mutex
+	sessions  map[string]*SessionState
+}
+
+// Creates an Engine.
+//
+// @param moderator Usually the {@link pkg.OpenTok OpenTok} instance.
+// @param rules The rules, evaluated in order for every callback.
+// @param config Dry-run mode and audit log.
+func NewEngine(moderator Moderator, rules []Rule, config Config) *Engine {
+	if config.Audit == nil {
+		config.Audit = NewMemoryAuditLog(0)
+	}
+	return &Engine{
+		moderator: moderator,
+		rules:     rules,
+		dryRun:    config.DryRun,
+		audit:     config.Audit,
+		sessions:  make(map[string]*SessionState),
+	}
+}
+
+func (e *Engine) Audit() AuditLog {
+	return e.audit
+}
+
+// Updates the session state with a connectionCreated/connectionDestroyed callback, evaluates
+// the rules and acts on the violations.
+//
+// @return The audit entries of the decisions taken.
+func (e *Engine) HandleConnectionCallback(callback *pkg.ConnectionCallback) []*AuditEntry {
+	if callback == nil || callback.Connection == nil {
+		return nil
+	}
+	event := &Event{SessionId: callback.SessionID, Event: callback.Event, Connection: callback.Connection}
+	event.Data, _ = callback.Connection.ParseData()
+	return e.handle(event)
+}
+
+// Updates the session state with a streamCreated/streamDestroyed callback, evaluates the rules
+// and acts on the violations.
+//
+// @return The audit entries of the decisions taken.
+func (e *Engine) HandleStreamCallback(callback *pkg.StreamCallback) []*AuditEntry {
+	if callback == nil || callback.Stream == nil {
+		return nil
+	}
+	event := &Event{SessionId: callback.SessionID, Event: callback.Event, Stream: callback.Stream, Connection: callback.Stream.Connection}
+	if callback.Stream.Connection != nil {
+		event.Data, _ = callback.Stream.Connection.ParseData()
+	}
+	return e.handle(event)
+}
+
+// Forgets the state of a session, e.g. once the session ended.
+func (e *Engine) Forget(sessionId string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.sessions, sessionId)
+}
+
+func (e *Engine) handle(event *Event) []*AuditEntry {
+	e.mu.Lock()
+	state, ok := e.sessions[event.SessionId]
+	if !ok {
+		state = newSessionState()
+		e.sessions[event.SessionId] = state
+	}
+	switch event.Event {
+	case pkg.EventConnectionCreated:
+		state.Connections[event.Connection.ID] = event.Connection
+	case pkg.EventConnectionDestroyed:
+		delete(state.Connections, event.Connection.ID)
+	case pkg.EventStreamCreated:
+		state.Streams[event.Stream.ID] = event.Stream
+	case pkg.EventStreamDestroyed:
+		delete(state.Streams, event.Stream.ID)
+	}
+	type violation struct {
+		rule     Rule
+		decision *Decision
+	}
+	var violations []violation
+	for _, rule := range e.rules {
+		if decision := rule.Evaluate(state, event); decision != nil {
+			violations = append(violations, violation{rule, decision})
+		}
+	}
+	e.mu.Unlock()
+
+	entries := make([]*AuditEntry, 0, len(violations))
+	for _, v := range violations {
+		entry := &AuditEntry{
+			Time:      time.Now(),
+			SessionId: event.SessionId,
+			Event:     event.Event,
+			Rule:      v.rule.Name(),
+			Action:    v.decision.Action,
+			Target:    v.decision.Target,
+			Reason:    v.decision.Reason,
+			DryRun:    e.dryRun,
+		}
+		if !e.dryRun {
+			entry.Err = e.act(event.SessionId, v.decision)
+		}
+		e.audit.Record(entry)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (e *Engine) act(sessionId string, decision *Decision) error {
+	switch decision.Action {
+	case ActionMute:
+		return e.moderator.ForceMuteStream(sessionId, decision.Target)
+	default:
+		return e.moderator.ForceDisconnect(sessionId, decision.Target)
+	}
+}
@@ -0,0 +1,118 @@
+package moderation
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"github.com/VolodymyrPobochii/opentok-go/pkg"
+)
+
+type fakeModerator struct {
+	disconnected []string
+	muted        []string
+}
+
+func (f *fakeModerator) ForceDisconnect(sessionId, connectionId string) error {
+	f.disconnected = append(f.disconnected, connectionId)
+	return nil
+}
+
+func (f *fakeModerator) ForceMuteStream(sessionId, streamId string) error {
+	f.muted = append(f.muted, streamId)
+	return nil
+}
+
+func connection(id, uid string, createdAt int64) *pkg.Connection {
+	return &pkg.Connection{ID: id, CreatedAt: createdAt, Data: base64.StdEncoding.EncodeToString([]byte(uid + "&chat&call"))}
+}
+
+func connectionCallback(event string, c *pkg.Connection) *pkg.ConnectionCallback {
+	return &pkg.ConnectionCallback{Callback: pkg.Callback{SessionID: "1_session", Event: event}, Connection: c}
+}
+
+func streamCallback(event, id string, c *pkg.Connection) *pkg.StreamCallback {
+	return &pkg.StreamCallback{Callback: pkg.Callback{SessionID: "1_session", Event: event}, Stream: &pkg.Stream{ID: id, Connection: c}}
+}
+
+func TestEngine(t *testing.T) {
+	alice1, alice2 := connection("c1", "alice", 1), connection("c2", "alice", 2)
+	bob, mallory := connection("c3", "bob", 3), connection("c4", "mallory", 4)
+	invited := map[string]bool{"alice": true, "bob": true}
+	rules := []Rule{
+		&MaxPublishers{Limit: 1, Action: ActionMute},
+		&OneConnectionPerUser{DisconnectOldest: true},
+		&InvitedOnly{Invited: func(sessionId string, data *pkg.ConnectionData) bool { return invited[data.Uid] }},
+	}
+
+	tests := []struct {
+		name             string
+		dryRun           bool
+		wantDisconnected []string
+		wantMuted        []string
+	}{
+		{"enforce", false, []string{"c1", "c4", "c5"}, []string{"s2", "s3"}},
+		{"dry_run", true, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moderator := &fakeModerator{}
+			engine := NewEngine(moderator, rules, Config{DryRun: tt.dryRun})
+
+			engine.HandleConnectionCallback(connectionCallback(pkg.EventConnectionCreated, alice1))
+			engine.HandleConnectionCallback(connectionCallback(pkg.EventConnectionCreated, bob))
+			engine.HandleStreamCallback(streamCallback(pkg.EventStreamCreated, "s1", bob))
+			engine.HandleConnectionCallback(connectionCallback(pkg.EventConnectionCreated, alice2))
+			engine.HandleStreamCallback(streamCallback(pkg.EventStreamCreated, "s2", alice2))
+			engine.HandleStreamCallback(streamCallback(pkg.EventStreamDestroyed, "s1", bob))
+			engine.HandleStreamCallback(streamCallback(pkg.EventStreamCreated, "s3", alice2))
+			engine.HandleConnectionCallback(connectionCallback(pkg.EventConnectionCreated, mallory))
+			engine.HandleConnectionCallback(connectionCallback(pkg.EventConnectionCreated, &pkg.Connection{ID: "c5", Data: "%%%"}))
+
+			if !reflect.DeepEqual(moderator.disconnected, tt.wantDisconnected) {
+				t.Errorf("disconnected = %v, want %v", moderator.disconnected, tt.wantDisconnected)
+			}
+			if !reflect.DeepEqual(moderator.muted, tt.wantMuted) {
+				t.Errorf("muted = %v, want %v", moderator.muted, tt.wantMuted)
+			}
+
+			var got []string
+			for _, entry := range engine.Audit().(*MemoryAuditLog).Entries() {
+				if entry.DryRun != tt.dryRun {
+					t.Errorf("audit entry DryRun = %v, want %v", entry.DryRun, tt.dryRun)
+				}
+				got = append(got, entry.Rule+":"+entry.Target)
+			}
+			// a muted stream is still published, so s3 exceeds the limit too
+			want := []string{"oneConnectionPerUser:c1", "maxPublishers:s2", "maxPublishers:s3", "invitedOnly:c4", "invitedOnly:c5"}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("audit = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestInvitedOnly_nilInvited(t *testing.T) {
+	moderator := &fakeModerator{}
+	engine := NewEngine(moderator, []Rule{&InvitedOnly{}}, Config{})
+	engine.HandleConnectionCallback(connectionCallback(pkg.EventConnectionCreated, connection("c1", "alice", 1)))
+	engine.HandleConnectionCallback(connectionCallback(pkg.EventConnectionCreated, &pkg.Connection{ID: "c2", Data: "%%%"}))
+	if len(moderator.disconnected) != 0 {
+		t.Errorf("disconnected = %v, want none without Invited", moderator.disconnected)
+	}
+}
+
+func TestMaxPublishers_noConnection(t *testing.T) {
+	moderator := &fakeModerator{}
+	engine := NewEngine(moderator, []Rule{&MaxPublishers{Limit: 1}}, Config{})
+	engine.HandleStreamCallback(streamCallback(pkg.EventStreamCreated, "s1", connection("c1", "alice", 1)))
+	// the streams of a StreamWatcher have no connection
+	engine.HandleStreamCallback(streamCallback(pkg.EventStreamCreated, "s2", nil))
+	engine.HandleStreamCallback(streamCallback(pkg.EventStreamCreated, "s3", connection("c3", "bob", 3)))
+	if want := []string{"c3"}; !reflect.DeepEqual(moderator.disconnected, want) {
+		t.Errorf("disconnected = %v, want %v", moderator.disconnected, want)
+	}
+	if want := []string{"s2"}; !reflect.DeepEqual(moderator.muted, want) {
+		t.Errorf("muted = %v, want %v", moderator.muted, want)
+	}
+}
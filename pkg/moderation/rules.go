@@ -0,0 +1,126 @@
+package moderation
+
+import (
+	"fmt"
+
+	"github.com/VolodymyrPobochii/opentok-go/pkg"
+)
+
+// What the engine does about a rule violation.
+type Action string
+
+const (
+	ActionDisconnect Action = "disconnect"
+	ActionMute       Action = "mute"
+)
+
+// A connection or stream event, as seen by the rules.
+type Event struct {
+	SessionId string
+	Event     string
+	// The connection of the event or the one publishing the stream. Nil for the stream events
+	// of a {@link pkg.StreamWatcher StreamWatcher}, whose streams carry no connection.
+	Connection *pkg.Connection
+	Stream     *pkg.Stream
+	// The decoded connection data, nil when it cannot be decoded.
+	Data *pkg.ConnectionData
+}
+
+// The action a rule asks for.
+type Decision struct {
+	Action Action
+	// The connection ID for ActionDisconnect, the stream ID for ActionMute.
+	Target string
+	Reason string
+}
+
+// A moderation rule, evaluated after the session state was updated with the event.
+type Rule interface {
+	Name() string
+	// Returns nil when the event does not violate the rule.
+	Evaluate(state *SessionState, event *Event) *Decision
+}
+
+// Limits the number of streams published in a session. The stream that exceeds the limit is
+// muted or its connection disconnected.
+type MaxPublishers struct {
+	Limit int
+	// ActionDisconnect (default) or ActionMute. A stream whose connection is unknown, e.g. from
+	// a {@link pkg.StreamWatcher StreamWatcher}, cannot be disconnected and is muted instead.
+	Action Action
+}
+
+func (r *MaxPublishers) Name() string {
+	return "maxPublishers"
+}
+
+func (r *MaxPublishers) Evaluate(state *SessionState, event *Event) *Decision {
+	if event.Event != pkg.EventStreamCreated || event.Stream == nil || len(state.Streams) <= r.Limit {
+		return nil
+	}
+	reason := fmt.Sprintf("%d streams published, at most %d allowed", len(state.Streams), r.Limit)
+	if r.Action == ActionMute {
+		return &Decision{Action: ActionMute, Target: event.Stream.ID, Reason: reason}
+	}
+	if event.Connection == nil {
+		return &Decision{Action: ActionMute, Target: event.Stream.ID, Reason: reason + ", the connection is unknown"}
+	}
+	return &Decision{Action: ActionDisconnect, Target: event.Connection.ID, Reason: reason}
+}
+
+// Allows one connection per user ID (the uid of the connection data). The new connection is
+// disconnected, or the older one with DisconnectOldest.
+type OneConnectionPerUser struct {
+	DisconnectOldest bool
+}
+
+func (r *OneConnectionPerUser) Name() string {
+	return "oneConnectionPerUser"
+}
+
+func (r *OneConnectionPerUser) Evaluate(state *SessionState, event *Event) *Decision {
+	if event.Event != pkg.EventConnectionCreated || event.Connection == nil || event.Data == nil {
+		return nil
+	}
+	var oldest *pkg.Connection
+	for _, connection := range state.UserConnections(event.Data.Uid) {
+		if connection.ID == event.Connection.ID {
+			continue
+		}
+		if oldest == nil || connection.CreatedAt < oldest.CreatedAt {
+			oldest = connection
+		}
+	}
+	if oldest == nil {
+		return nil
+	}
+	reason := fmt.Sprintf("user %s is already connected", event.Data.Uid)
+	if r.DisconnectOldest {
+		return &Decision{Action: ActionDisconnect, Target: oldest.ID, Reason: reason}
+	}
+	return &Decision{Action: ActionDisconnect, Target: event.Connection.ID, Reason: reason}
+}
+
+// Disconnects connections whose data cannot be decoded or does not match an invited user.
+type InvitedOnly struct {
+	// Whether the user of the connection data is invited to the session. The rule does nothing
+	// when it is nil.
+	Invited func(sessionId string, data *pkg.ConnectionData) bool
+}
+
+func (r *InvitedOnly) Name() string {
+	return "invitedOnly"
+}
+
+func (r *InvitedOnly) Evaluate(state *SessionState, event *Event) *Decision {
+	if r.Invited == nil || event.Event != pkg.EventConnectionCreated || event.Connection == nil {
+		return nil
+	}
+	if event.Data == nil {
+		return &Decision{Action: ActionDisconnect, Target: event.Connection.ID, Reason: "connection data cannot be decoded"}
+	}
+	if !r.Invited(event.SessionId, event.Data) {
+		return &Decision{Action: ActionDisconnect, Target: event.Connection.ID, Reason: fmt.Sprintf("user %s is not invited", event.Data.Uid)}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestOpenTok_ForceDisconnect(t *testing.T) {
+	ot := newTestOpenTok(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete && r.URL.Path == "/v2/project/"+testApiKey+"/session/1_session/connection/conn1" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":404,"message":"Not found. The client specified by the connectionId property is not connected to the session."}`))
+	})
+	tests := []struct {
+		name         string
+		sessionId    string
+		connectionId string
+		wantErr      error
+	}{
+		{"disconnect", "1_session", "conn1", nil},
+		{"unknown_connection", "1_session", "conn2", ErrorConnectionNotFound},
+		{"no_session_id", "", "conn1", ErrorMissingSessionId},
+		{"no_connection_id", "1_session", "", ErrorMissingConnectionId},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ot.ForceDisconnect(tt.sessionId, tt.connectionId); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ForceDisconnect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	session := NewSession(ot, "1_session", nil)
+	if err := session.ForceDisconnect("conn1"); err != nil {
+		t.Errorf("Session.ForceDisconnect() error = %v", err)
+	}
+	if err := NewSession(nil, "1_session", nil).ForceDisconnect("conn1"); err != ErrorSessionNotBound {
+		t.Errorf("Session.ForceDisconnect() error = %v, want %v", err, ErrorSessionNotBound)
+	}
+}
+
+func TestOpenTok_ForceMute(t *testing.T) {
+	var gotBody map[string]interface{}
+	ot := newTestOpenTok(t, func(w http.ResponseWriter, r *http.Request) {
+		gotBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		switch {
+		case r.Method != http.MethodPost:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.URL.Path == "/v2/project/"+testApiKey+"/session/1_session/stream/stream1/mute",
+			r.URL.Path == "/v2/project/"+testApiKey+"/session/1_session/mute":
+			_, _ = w.Write([]byte(`{"applicationId":"` + testApiKey + `","status":"ACTIVE"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		call     func() error
+		wantBody map[string]interface{}
+		wantErr  error
+	}{
+		{
+			"mute_stream",
+			func() error { return ot.ForceMuteStream("1_session", "stream1") },
+			nil, nil,
+		},
+		{
+			"mute_unknown_stream",
+			func() error { return ot.ForceMuteStream("1_session", "stream2") },
+			nil, ErrorStreamNotFound,
+		},
+		{
+			"mute_all",
+			func() error { return ot.ForceMuteAll("1_session", []string{"stream1"}) },
+			map[string]interface{}{"active": true, "excludedStreamIds": []interface{}{"stream1"}},
+			nil,
+		},
+		{
+			"mute_all_unknown_session",
+			func() error { return ot.ForceMuteAll("1_other", nil) },
+			map[string]interface{}{"active": true}, ErrorSessionNotFound,
+		},
+		{
+			"disable",
+			func() error { return ot.DisableForceMute("1_session") },
+			map[string]interface{}{"active": false},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(gotBody, tt.wantBody) {
+				t.Errorf("request body = %v, want %v", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
@@ -17,6 +17,9 @@ var (
 	ErrorNoApiKey       = errors.New("token cannot be generated unless the session belongs to the API Key")
 	ErrorWrongMediaMode = errors.New("a session with always archive mode must also have the routed media mode")
 	ErrorInvalidIPv4    = errors.New("invalid arguments when calling CreateSession, location must be an IPv4 address")
+	ErrorInvalidSession = errors.New("invalid sessionId, it cannot be decoded")
+	ErrorE2EEMediaMode  = errors.New("an end-to-end encrypted session must have the routed media mode")
+	ErrorE2EEArchive    = errors.New("an end-to-end encrypted session cannot have the always archive mode")
 )
 
 type SessionInfo struct {
@@ -88,6 +91,9 @@ func NewOpenTok(apiKey, apiSecret string, env interface{}) *OpenTok {
 // @param     {string}         sessionId
 // @returns   {?SessionInfo}    sessionInfo
 func (ot *OpenTok) decodeSessionId(sessionId string) (*SessionInfo, error) {
+	if len(sessionId) < 3 {
+		return nil, ErrorInvalidSession
+	}
 	// remove sentinal (e.g. '1_', '2_')
 	sessionId = sessionId[2:]
 
@@ -105,6 +111,9 @@ func (ot *OpenTok) decodeSessionId(sessionId string) (*SessionInfo, error) {
 
 	// separate fields
 	fields := strings.Split(string(bytes), "~")
+	if len(fields) < 4 {
+		return nil, ErrorInvalidSession
+	}
 	timestamp, err := strconv.ParseInt(fields[3], 10, 64)
 	if err != nil {
 		return nil, err
@@ -119,8 +128,8 @@ func (ot *OpenTok) decodeSessionId(sessionId string) (*SessionInfo, error) {
 
 func (ot *OpenTok) CreateSession(options map[string]interface{}) (*Session, error) {
 	// whitelist the keys allowed
-	src := map[string]interface{}{"mediaMode": "relayed", "archiveMode": "manual"}
-	keys := []string{"mediaMode", "archiveMode", "location"}
+	src := map[string]interface{}{"mediaMode": "relayed", "archiveMode": "manual", "e2ee": false}
+	keys := []string{"mediaMode", "archiveMode", "location", "e2ee"}
 	if options == nil {
 		options = make(map[string]interface{}, len(src))
 	}
@@ -142,6 +151,16 @@ func (ot *OpenTok) CreateSession(options map[string]interface{}) (*Session, erro
 		return nil, ErrorInvalidIPv4
 	}
 
+	// end-to-end encryption needs the media router and is incompatible with archiving
+	e2ee, _ := options["e2ee"].(bool)
+	options["e2ee"] = e2ee
+	if e2ee && options["mediaMode"] != "routed" {
+		return nil, ErrorE2EEMediaMode
+	}
+	if e2ee && options["archiveMode"] == "always" {
+		return nil, ErrorE2EEArchive
+	}
+
 	// rename mediaMode -> p2p.preference
 	// store backup for use in constructing Session
 	backupOpts := Clone(options)
@@ -150,13 +169,18 @@ func (ot *OpenTok) CreateSession(options map[string]interface{}) (*Session, erro
 	mediaModeToParam := map[string]string{"routed": "disabled", "relayed": "enabled"}
 	options["p2p.preference"] = mediaModeToParam[options["mediaMode"].(string)]
 	delete(options, "mediaMode")
+	if !e2ee {
+		delete(options, "e2ee")
+	}
 
-	sessionId, err := ot.client.createSession(options)
+	sessionResponse, err := ot.client.createSession(options)
 	if err != nil {
 		return nil, errors.New(fmt.Sprintf("Failed to CreateSession. %v", err))
 	}
-	log.Println("created session:", sessionId)
-	return NewSession(ot, sessionId, backupOpts), nil
+	log.Println("created session:", sessionResponse.SessionId)
+	session := NewSession(ot, sessionResponse.SessionId, backupOpts)
+	session.metadata = newSessionMetadata(sessionResponse)
+	return session, nil
 }
 
 // Creates a token for connecting to an OpenTok session. In order to authenticate a user
@@ -1,6 +1,9 @@
 package pkg
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 	"time"
@@ -197,3 +200,48 @@ func TestOpenTok_generateToken(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenTok_CreateSession_options(t *testing.T) {
+	var requestBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"session_id":"1_test","project_id":"46513602","create_dt":"Mon Mar 30 07:22:19 UTC 2020","media_server_hostname":"mantis.example.com"}]`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		options  map[string]interface{}
+		wantE2EE bool
+		wantErr  error
+	}{
+		{"defaults", nil, false, nil},
+		{"e2ee_routed", map[string]interface{}{"mediaMode": "routed", "e2ee": true}, true, nil},
+		{"e2ee_relayed", map[string]interface{}{"e2ee": true}, false, ErrorE2EEMediaMode},
+		{"e2ee_archive_always", map[string]interface{}{"mediaMode": "routed", "archiveMode": "always", "e2ee": true}, false, ErrorE2EEArchive},
+		{"always_relayed", map[string]interface{}{"archiveMode": "always"}, false, ErrorWrongMediaMode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ot := NewOpenTok("46513602", "d06eaf53e214c105f02f2615170a04e08bf39aa6", server.URL)
+			got, err := ot.CreateSession(tt.options)
+			if err != tt.wantErr {
+				t.Fatalf("CreateSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.E2EE() != tt.wantE2EE {
+				t.Errorf("CreateSession() E2EE = %v, want %v", got.E2EE(), tt.wantE2EE)
+			}
+			if _, ok := requestBody["e2ee"]; ok != tt.wantE2EE {
+				t.Errorf("CreateSession() request e2ee = %v, want %v", requestBody["e2ee"], tt.wantE2EE)
+			}
+			if got.Metadata() == nil || got.Metadata().MediaServerHostname != "mantis.example.com" {
+				t.Errorf("CreateSession() Metadata = %+v", got.Metadata())
+			}
+		})
+	}
+}
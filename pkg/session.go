@@ -1,24 +1,276 @@
 package pkg
 
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	MediaModeRouted   = "routed"
+	MediaModeRelayed  = "relayed"
+	ArchiveModeManual = "manual"
+	ArchiveModeAlways = "always"
+)
+
+var (
+	ErrorSessionNotBound = errors.New("the session is not bound to an OpenTok instance, use RestoreSession")
+	ErrorSessionApiKey   = errors.New("the session does not belong to the API Key")
+)
+
 type Session struct {
 	ot         *OpenTok
 	sessionId  string
 	properties map[string]interface{}
+	metadata   *SessionMetadata
+}
+
+// Metadata returned by the OpenTok API when the session was created. It describes where the
+// session is hosted (media and messaging servers, ICE servers) and when it was created.
+type SessionMetadata struct {
+	SessionId     string    `json:"sessionId"`
+	ProjectId     string    `json:"projectId"`
+	PartnerId     string    `json:"partnerId"`
+	SessionStatus string    `json:"sessionStatus,omitempty"`
+	CreateDT      time.Time `json:"createDT"`
+	// The create_dt value as reported by the API, CreateDT is zero when it cannot be parsed.
+	RawCreateDT         string      `json:"rawCreateDT,omitempty"`
+	MediaServerUrl      string      `json:"mediaServerUrl,omitempty"`
+	MediaServerHostname string      `json:"mediaServerHostname,omitempty"`
+	MessagingServerUrl  string      `json:"messagingServerUrl,omitempty"`
+	MessagingUrl        string      `json:"messagingUrl,omitempty"`
+	SymphonyAddress     string      `json:"symphonyAddress,omitempty"`
+	IceServer           string      `json:"iceServer,omitempty"`
+	IceServers          interface{} `json:"iceServers,omitempty"`
+	IceCredentialExp    int64       `json:"iceCredentialExpiration,omitempty"`
+	Properties          interface{} `json:"properties,omitempty"`
+}
+
+// layouts used by the OpenTok API for the create_dt field, e.g. "Mon Mar 30 07:22:19 PDT 2020"
+var createDTLayouts = []string{time.UnixDate, time.RFC1123, time.RFC3339}
+
+// offsets of the zone abbreviations used in create_dt, time.Parse gives an abbreviation that
+// the local location does not know a zero offset
+var createDTZones = map[string]int{
+	"UTC": 0,
+	"GMT": 0,
+	"PST": -8 * 60 * 60,
+	"PDT": -7 * 60 * 60,
+	"MST": -7 * 60 * 60,
+	"MDT": -6 * 60 * 60,
+	"CST": -6 * 60 * 60,
+	"CDT": -5 * 60 * 60,
+	"EST": -5 * 60 * 60,
+	"EDT": -4 * 60 * 60,
+}
+
+var ErrorCreateDT = errors.New("invalid create_dt, unknown format or time zone")
+
+func parseCreateDT(createDT string) (time.Time, error) {
+	for _, layout := range createDTLayouts {
+		t, err := time.Parse(layout, createDT)
+		if err != nil {
+			continue
+		}
+		if layout == time.RFC3339 {
+			return t, nil
+		}
+		name, offset := t.Zone()
+		if known, ok := createDTZones[name]; ok {
+			offset = known
+		} else if offset == 0 {
+			// an abbreviation unknown to both the table and the local location
+			return time.Time{}, ErrorCreateDT
+		}
+		year, month, day := t.Date()
+		return time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.FixedZone(name, offset)), nil
+	}
+	return time.Time{}, ErrorCreateDT
+}
+
+func newSessionMetadata(response *CreateSessionResponse) *SessionMetadata {
+	if response == nil {
+		return nil
+	}
+	metadata := &SessionMetadata{
+		SessionId:           response.SessionId,
+		ProjectId:           response.ProjectId,
+		PartnerId:           response.PartnerId,
+		SessionStatus:       response.SessionStatus,
+		RawCreateDT:         response.CreateDT,
+		MediaServerUrl:      response.MediaServerUrl,
+		MediaServerHostname: response.MediaServerHostname,
+		MessagingServerUrl:  response.MessagingServerUrl,
+		MessagingUrl:        response.MessagingUrl,
+		SymphonyAddress:     response.SymphonyAddress,
+		IceServer:           response.IceServer,
+		IceServers:          response.IceServers,
+		IceCredentialExp:    response.IceCredentialExp,
+		Properties:          response.Properties,
+	}
+	// unparseable values are kept in RawCreateDT
+	metadata.CreateDT, _ = parseCreateDT(response.CreateDT)
+	return metadata
 }
 
 func (s *Session) Id() string {
 	return s.sessionId
 }
 
+// The media mode of the session, either "routed" or "relayed".
+func (s *Session) MediaMode() string {
+	return s.stringProperty("mediaMode")
+}
+
+// The archive mode of the session, either "manual" or "always".
+func (s *Session) ArchiveMode() string {
+	return s.stringProperty("archiveMode")
+}
+
+// The location hint (an IPv4 address) the session was created with, or an empty string.
+func (s *Session) Location() string {
+	return s.stringProperty("location")
+}
+
+// Whether the session was created with end-to-end encryption enabled.
+func (s *Session) E2EE() bool {
+	e2ee, _ := s.properties["e2ee"].(bool)
+	return e2ee
+}
+
+// Returns a copy of the properties the session was created with.
+func (s *Session) Properties() map[string]interface{} {
+	return Clone(s.properties)
+}
+
+func (s *Session) stringProperty(key string) string {
+	if value, ok := s.properties[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// Returns the metadata the OpenTok API reported when the session was created, or nil if the
+// session was not created through {@link OpenTok#CreateSession OpenTok.CreateSession()}.
+func (s *Session) Metadata() *SessionMetadata {
+	return s.metadata
+}
+
 // Represents an OpenTok session. Use the {@link OpenTok#CreateSession OpenTok.CreateSession()}
 // method to create an OpenTok session. The <code>sessionId</code> property of the Session object
 // is the session ID.
 // @property {String} sessionId The session ID.
 // @class Session
 func NewSession(ot *OpenTok, sessionId string, properties map[string]interface{}) *Session {
-	return &Session{ot, sessionId, properties}
+	return &Session{ot: ot, sessionId: sessionId, properties: properties}
 }
 
-func (s *Session) generateToken(options map[string]interface{}) (string, error) {
+// Rebuilds a Session from a session ID and the properties it was created with, e.g. after
+// loading them from storage. The session is bound to ot so it can generate tokens and call
+// the OpenTok REST API.
+//
+// @param ot The OpenTok instance the session belongs to.
+// @param sessionId The session ID.
+// @param properties The session properties (<code>mediaMode</code>, <code>archiveMode</code>,
+// <code>location</code>, <code>e2ee</code>), as returned by {@link Session#Properties Session.Properties()}.
+func RestoreSession(ot *OpenTok, sessionId string, properties map[string]interface{}) (*Session, error) {
+	if err := checkSessionOwner(ot, sessionId); err != nil {
+		return nil, err
+	}
+	if properties == nil {
+		properties = make(map[string]interface{})
+	}
+	properties = Pick(Defaults(Clone(properties), map[string]interface{}{
+		"mediaMode":   MediaModeRelayed,
+		"archiveMode": ArchiveModeManual,
+		"e2ee":        false,
+	}), []string{"mediaMode", "archiveMode", "location", "e2ee"})
+	return NewSession(ot, sessionId, properties), nil
+}
+
+// Binds a session decoded with UnmarshalJSON to ot, so it can generate tokens and call the
+// OpenTok REST API. Unlike {@link RestoreSession RestoreSession()}, the metadata of the session
+// is kept.
+//
+// @param ot The OpenTok instance the session belongs to.
+//
+// @return ErrorSessionApiKey when the session belongs to another project, the session is left
+// unchanged on error.
+func (s *Session) Bind(ot *OpenTok) error {
+	if err := checkSessionOwner(ot, s.sessionId); err != nil {
+		return err
+	}
+	s.ot = ot
+	return nil
+}
+
+// checks that the session ID is one of the project of ot
+func checkSessionOwner(ot *OpenTok, sessionId string) error {
+	if ot == nil {
+		return ErrorSessionNotBound
+	}
+	if len(sessionId) == 0 {
+		return ErrorNoSessionId
+	}
+	decoded, err := ot.decodeSessionId(sessionId)
+	if err != nil {
+		return err
+	}
+	if decoded.apiKey != ot.apiKey {
+		return ErrorSessionApiKey
+	}
+	return nil
+}
+
+// Creates a token for connecting to this session.
+// See {@link OpenTok#GenerateToken OpenTok.GenerateToken()} for the supported options.
+func (s *Session) GenerateToken(options map[string]interface{}) (string, error) {
+	if s.ot == nil {
+		return "", ErrorSessionNotBound
+	}
 	return s.ot.GenerateToken(s.sessionId, options)
 }
+
+type sessionJSON struct {
+	SessionId   string           `json:"sessionId"`
+	MediaMode   string           `json:"mediaMode"`
+	ArchiveMode string           `json:"archiveMode"`
+	Location    string           `json:"location,omitempty"`
+	E2EE        bool             `json:"e2ee,omitempty"`
+	Metadata    *SessionMetadata `json:"metadata,omitempty"`
+}
+
+// Encodes the session ID, properties and metadata so the session can be stored and later
+// rebuilt with UnmarshalJSON and Bind.
+func (s *Session) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&sessionJSON{
+		SessionId:   s.sessionId,
+		MediaMode:   s.MediaMode(),
+		ArchiveMode: s.ArchiveMode(),
+		Location:    s.Location(),
+		E2EE:        s.E2EE(),
+		Metadata:    s.metadata,
+	})
+}
+
+// Decodes a session encoded with MarshalJSON. A session decoded into a zero Session is not
+// bound to an OpenTok instance; call Bind to bind it and keep its metadata.
+func (s *Session) UnmarshalJSON(data []byte) error {
+	var decoded sessionJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	properties := map[string]interface{}{
+		"mediaMode":   decoded.MediaMode,
+		"archiveMode": decoded.ArchiveMode,
+		"location":    nil,
+		"e2ee":        decoded.E2EE,
+	}
+	if len(decoded.Location) != 0 {
+		properties["location"] = decoded.Location
+	}
+	s.sessionId = decoded.SessionId
+	s.properties = properties
+	s.metadata = decoded.Metadata
+	return nil
+}
@@ -0,0 +1,303 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrorPoolClosed         = errors.New("the session pool is closed")
+	ErrorPoolUnknownProfile = errors.New("unknown session pool profile")
+)
+
+// Creates OpenTok sessions, implemented by {@link OpenTok OpenTok}.
+type SessionCreator interface {
+	CreateSession(options map[string]interface{}) (*Session, error)
+}
+
+type SessionPoolConfig struct {
+	// Number of sessions kept pre-created for each profile (default 1).
+	Size int
+	// Maximum number of background CreateSession calls in flight across all profiles (default 1).
+	// The sessions Get creates on the spot are not limited, a caller never waits for a refill.
+	Concurrency int
+	// Delay before retrying after a failed CreateSession, doubled on every consecutive
+	// failure up to MaxRetryDelay (defaults 1s and 30s).
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+	// CreateSession options keyed by profile name, e.g. "routed" -> {"mediaMode": "routed"}.
+	Profiles map[string]map[string]interface{}
+}
+
+type SessionPoolStats struct {
+	// Get calls served from the pool.
+	Hits int64
+	// Get calls that had to create a session on the spot.
+	Misses int64
+	// Sessions created in the background.
+	Created int64
+	// Failed background CreateSession calls.
+	Failures int64
+}
+
+// Keeps a number of sessions pre-created for each option profile so that handing one out does
+// not cost a round trip to the OpenTok API. Sessions are refilled in the background.
+type SessionPool struct {
+	// accessed atomically, kept first for 64-bit alignment
+	hits     int64
+	misses   int64
+	created  int64
+	failures int64
+
+	creator  SessionCreator
+	config   SessionPoolConfig
+	sessions map[string]chan *Session
+	refill   map[string]chan struct{}
+	sem      chan struct{}
+	done     chan struct{}
+	// guards closed so that no goroutine is added to wg once Shutdown waits for it
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// Creates a SessionPool and starts filling it in the background.
+//
+// @param creator Usually the {@link OpenTok OpenTok} instance.
+// @param config The pool size, refill concurrency and option profiles.
+func NewSessionPool(creator SessionCreator, config SessionPoolConfig) *SessionPool {
+	if config.Size <= 0 {
+		config.Size = 1
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = time.Second
+	}
+	if config.MaxRetryDelay <= 0 {
+		config.MaxRetryDelay = 30 * time.Second
+	}
+	if config.MaxRetryDelay < config.RetryDelay {
+		config.MaxRetryDelay = config.RetryDelay
+	}
+	pool := &SessionPool{
+		creator:  creator,
+		config:   config,
+		sessions: make(map[string]chan *Session, len(config.Profiles)),
+		refill:   make(map[string]chan struct{}, len(config.Profiles)),
+		sem:      make(chan struct{}, config.Concurrency),
+		done:     make(chan struct{}),
+	}
+	for profile := range config.Profiles {
+		pool.sessions[profile] = make(chan *Session, config.Size)
+		pool.refill[profile] = make(chan struct{}, 1)
+	}
+	for profile := range config.Profiles {
+		pool.wg.Add(1)
+		go pool.fill(profile)
+	}
+	return pool
+}
+
+// Hands out a session for the given profile. A pre-created session is returned when one is
+// available, otherwise a session is created on the spot. Either way a background refill is
+// triggered.
+func (p *SessionPool) Get(ctx context.Context, profile string) (*Session, error) {
+	sessions, ok := p.sessions[profile]
+	if !ok {
+		if p.isClosed() {
+			return nil, ErrorPoolClosed
+		}
+		return nil, ErrorPoolUnknownProfile
+	}
+	// tracks the on-demand CreateSession call below, Shutdown waits for it
+	if !p.track() {
+		return nil, ErrorPoolClosed
+	}
+	defer p.triggerRefill(profile)
+	tracked := true
+	defer func() {
+		if tracked {
+			p.wg.Done()
+		}
+	}()
+
+	select {
+	case session := <-sessions:
+		atomic.AddInt64(&p.hits, 1)
+		return session, nil
+	default:
+	}
+
+	atomic.AddInt64(&p.misses, 1)
+	type result struct {
+		session *Session
+		err     error
+	}
+	results := make(chan result, 1)
+	go func() {
+		session, err := p.creator.CreateSession(Clone(p.config.Profiles[profile]))
+		results <- result{session, err}
+	}()
+	select {
+	case r := <-results:
+		return r.session, r.err
+	case <-ctx.Done():
+		// keep the session once it arrives rather than wasting it, the goroutine now owns the
+		// tracking of the call
+		tracked = false
+		go func() {
+			defer p.wg.Done()
+			if r := <-results; r.err == nil {
+				p.put(profile, r.session)
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// adds a goroutine to wg, false once the pool is shut down
+func (p *SessionPool) track() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	p.wg.Add(1)
+	return true
+}
+
+func (p *SessionPool) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+// Number of pre-created sessions currently available for the profile.
+func (p *SessionPool) Available(profile string) int {
+	return len(p.sessions[profile])
+}
+
+func (p *SessionPool) Stats() SessionPoolStats {
+	return SessionPoolStats{
+		Hits:     atomic.LoadInt64(&p.hits),
+		Misses:   atomic.LoadInt64(&p.misses),
+		Created:  atomic.LoadInt64(&p.created),
+		Failures: atomic.LoadInt64(&p.failures),
+	}
+}
+
+// Stops refilling the pool and waits for in-flight CreateSession calls, including the ones of
+// Get, to finish or for ctx to end. Get returns ErrorPoolClosed afterwards and the sessions
+// created after the shutdown are dropped.
+func (p *SessionPool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.done)
+	}
+	p.mu.Unlock()
+	stopped := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *SessionPool) triggerRefill(profile string) {
+	select {
+	case p.refill[profile] <- struct{}{}:
+	default:
+	}
+}
+
+func (p *SessionPool) put(profile string, session *Session) {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+	select {
+	case p.sessions[profile] <- session:
+	default:
+	}
+}
+
+// keeps the profile filled, up to Size sessions are created in parallel within the limit of sem
+func (p *SessionPool) fill(profile string) {
+	defer p.wg.Done()
+	delay := p.config.RetryDelay
+	// the creates in flight report to results, which never blocks them
+	pending := 0
+	results := make(chan error, p.config.Size)
+	// set while backing off after a failed create
+	var retry <-chan time.Time
+	finished := func(err error) {
+		pending--
+		if err != nil {
+			retry = time.After(delay)
+			if delay *= 2; delay > p.config.MaxRetryDelay {
+				delay = p.config.MaxRetryDelay
+			}
+			return
+		}
+		delay = p.config.RetryDelay
+	}
+	for {
+		if retry == nil && len(p.sessions[profile])+pending < p.config.Size {
+			select {
+			case p.sem <- struct{}{}:
+				// both cases may be ready, no session is created once the pool is shut down
+				select {
+				case <-p.done:
+					<-p.sem
+					return
+				default:
+				}
+				pending++
+				p.wg.Add(1)
+				go p.create(profile, results)
+			case err := <-results:
+				finished(err)
+			case <-p.done:
+				return
+			}
+			continue
+		}
+
+		select {
+		case err := <-results:
+			finished(err)
+		case <-p.refill[profile]:
+		case <-retry:
+			retry = nil
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// creates a session in the background, the slot of sem is held by the caller
+func (p *SessionPool) create(profile string, results chan<- error) {
+	defer p.wg.Done()
+	session, err := p.creator.CreateSession(Clone(p.config.Profiles[profile]))
+	<-p.sem
+	if err != nil {
+		atomic.AddInt64(&p.failures, 1)
+	} else {
+		atomic.AddInt64(&p.created, 1)
+		p.put(profile, session)
+	}
+	// reported once the session is in the pool so that fill never counts it twice
+	results <- err
+}
@@ -0,0 +1,165 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSessionCreator struct {
+	mu      sync.Mutex
+	count   int
+	err     error
+	options []map[string]interface{}
+	// when set, CreateSession blocks until it is closed
+	gate chan struct{}
+	// CreateSession calls waiting for the gate
+	waiting int
+}
+
+func (f *fakeSessionCreator) CreateSession(options map[string]interface{}) (*Session, error) {
+	if f.gate != nil {
+		f.mu.Lock()
+		f.waiting++
+		f.mu.Unlock()
+		<-f.gate
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.count++
+	f.options = append(f.options, options)
+	return NewSession(nil, fmt.Sprintf("session-%d", f.count), options), nil
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSessionPool_Get(t *testing.T) {
+	creator := &fakeSessionCreator{}
+	pool := NewSessionPool(creator, SessionPoolConfig{
+		Size:        2,
+		Concurrency: 2,
+		Profiles: map[string]map[string]interface{}{
+			"routed":  {"mediaMode": "routed"},
+			"relayed": {"mediaMode": "relayed"},
+		},
+	})
+	defer pool.Shutdown(context.Background())
+
+	waitFor(t, func() bool { return pool.Available("routed") == 2 && pool.Available("relayed") == 2 })
+
+	session, err := pool.Get(context.Background(), "routed")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if session.MediaMode() != "routed" {
+		t.Errorf("Get() MediaMode = %v, want routed", session.MediaMode())
+	}
+	if stats := pool.Stats(); stats.Hits != 1 || stats.Misses != 0 {
+		t.Errorf("Stats() = %+v, want 1 hit", stats)
+	}
+	waitFor(t, func() bool { return pool.Available("routed") == 2 })
+
+	if _, err := pool.Get(context.Background(), "unknown"); err != ErrorPoolUnknownProfile {
+		t.Errorf("Get() error = %v, want %v", err, ErrorPoolUnknownProfile)
+	}
+}
+
+func TestSessionPool_miss(t *testing.T) {
+	creator := &fakeSessionCreator{err: errors.New("unavailable")}
+	pool := NewSessionPool(creator, SessionPoolConfig{
+		RetryDelay: time.Hour,
+		Profiles:   map[string]map[string]interface{}{"relayed": {}},
+	})
+
+	if _, err := pool.Get(context.Background(), "relayed"); err == nil {
+		t.Errorf("Get() error = nil, want creator error")
+	}
+	waitFor(t, func() bool { return pool.Stats().Failures > 0 })
+	if stats := pool.Stats(); stats.Misses != 1 || stats.Hits != 0 {
+		t.Errorf("Stats() = %+v, want 1 miss", stats)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if _, err := pool.Get(context.Background(), "relayed"); err != ErrorPoolClosed {
+		t.Errorf("Get() error = %v, want %v", err, ErrorPoolClosed)
+	}
+}
+
+func TestSessionPool_Shutdown(t *testing.T) {
+	creator := &fakeSessionCreator{gate: make(chan struct{})}
+	pool := NewSessionPool(creator, SessionPoolConfig{
+		Profiles: map[string]map[string]interface{}{"routed": {}},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Get(ctx, "routed"); err != context.DeadlineExceeded {
+		t.Fatalf("Get() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// the refill and the create of Get are in flight
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(creator.gate)
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	creator.mu.Lock()
+	defer creator.mu.Unlock()
+	if creator.count != 2 {
+		t.Errorf("CreateSession() calls = %d when Shutdown returned, want 2", creator.count)
+	}
+	// the sessions created after the shutdown are dropped
+	if available := pool.Available("routed"); available != 0 {
+		t.Errorf("Available() = %d, want 0", available)
+	}
+}
+
+func TestSessionPool_concurrency(t *testing.T) {
+	creator := &fakeSessionCreator{gate: make(chan struct{})}
+	pool := NewSessionPool(creator, SessionPoolConfig{
+		Size:        5,
+		Concurrency: 3,
+		Profiles:    map[string]map[string]interface{}{"routed": {}},
+	})
+	defer pool.Shutdown(context.Background())
+
+	waiting := func() int {
+		creator.mu.Lock()
+		defer creator.mu.Unlock()
+		return creator.waiting
+	}
+	// a single profile is refilled by parallel creates, bounded by Concurrency
+	waitFor(t, func() bool { return waiting() == 3 })
+	time.Sleep(10 * time.Millisecond)
+	if got := waiting(); got != 3 {
+		t.Errorf("CreateSession() calls in flight = %d, want 3", got)
+	}
+	close(creator.gate)
+	waitFor(t, func() bool { return pool.Available("routed") == 5 })
+	if stats := pool.Stats(); stats.Created != 5 {
+		t.Errorf("Stats() = %+v, want 5 created", stats)
+	}
+}
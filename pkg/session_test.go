@@ -0,0 +1,214 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_parseCreateDT(t *testing.T) {
+	type args struct {
+		createDT string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    time.Time
+		wantErr error
+	}{
+		{"unix_date", args{"Mon Mar 30 07:22:19 UTC 2020"}, time.Date(2020, 3, 30, 7, 22, 19, 0, time.UTC), nil},
+		{"unix_date_pdt", args{"Mon Mar 30 07:22:19 PDT 2020"}, time.Date(2020, 3, 30, 14, 22, 19, 0, time.UTC), nil},
+		{"unix_date_est", args{"Mon Jan 06 07:22:19 EST 2020"}, time.Date(2020, 1, 6, 12, 22, 19, 0, time.UTC), nil},
+		{"rfc1123_pst", args{"Mon, 06 Jan 2020 07:22:19 PST"}, time.Date(2020, 1, 6, 15, 22, 19, 0, time.UTC), nil},
+		{"rfc3339", args{"2020-03-30T07:22:19Z"}, time.Date(2020, 3, 30, 7, 22, 19, 0, time.UTC), nil},
+		{"rfc3339_offset", args{"2020-03-30T07:22:19-07:00"}, time.Date(2020, 3, 30, 14, 22, 19, 0, time.UTC), nil},
+		{"unknown_zone", args{"Mon Mar 30 07:22:19 XYZ 2020"}, time.Time{}, ErrorCreateDT},
+		{"empty", args{""}, time.Time{}, ErrorCreateDT},
+		{"garbage", args{"yesterday"}, time.Time{}, ErrorCreateDT},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCreateDT(tt.args.createDT)
+			if err != tt.wantErr {
+				t.Fatalf("parseCreateDT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseCreateDT() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newSessionMetadata(t *testing.T) {
+	type args struct {
+		response *CreateSessionResponse
+	}
+	tests := []struct {
+		name string
+		args args
+		want *SessionMetadata
+	}{
+		{"nil_response", args{nil}, nil},
+		{
+			"full_response",
+			args{&CreateSessionResponse{
+				SessionId:           "1_session",
+				ProjectId:           "46513602",
+				PartnerId:           "46513602",
+				CreateDT:            "Mon Mar 30 07:22:19 PDT 2020",
+				MediaServerUrl:      "https://mantis.example.com",
+				MediaServerHostname: "mantis.example.com",
+				MessagingUrl:        "wss://rumor.example.com",
+				IceCredentialExp:    86100,
+			}},
+			&SessionMetadata{
+				SessionId:           "1_session",
+				ProjectId:           "46513602",
+				PartnerId:           "46513602",
+				CreateDT:            time.Date(2020, 3, 30, 7, 22, 19, 0, time.FixedZone("PDT", -7*60*60)),
+				RawCreateDT:         "Mon Mar 30 07:22:19 PDT 2020",
+				MediaServerUrl:      "https://mantis.example.com",
+				MediaServerHostname: "mantis.example.com",
+				MessagingUrl:        "wss://rumor.example.com",
+				IceCredentialExp:    86100,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newSessionMetadata(tt.args.response); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newSessionMetadata() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_JSON(t *testing.T) {
+	createDT := time.Date(2020, 3, 30, 7, 22, 19, 0, time.UTC)
+	tests := []struct {
+		name    string
+		session *Session
+	}{
+		{
+			"routed_with_location",
+			&Session{
+				sessionId:  "2_MX40NjUxMzYwMn5-MTU4NDgwNjg4MTI2MX55NG5zMzBaN1loUi9YVHVmV1pkRkNkRTV-UH4",
+				properties: map[string]interface{}{"mediaMode": "routed", "archiveMode": "manual", "location": "10.1.200.30", "e2ee": true},
+				metadata:   &SessionMetadata{SessionId: "2_MX40", ProjectId: "46513602", CreateDT: createDT},
+			},
+		},
+		{
+			"relayed_no_metadata",
+			&Session{
+				sessionId:  "2_MX40NjUxMzYwMn5-MTU4NDgwNjg4MTI2MX55NG5zMzBaN1loUi9YVHVmV1pkRkNkRTV-UH4",
+				properties: map[string]interface{}{"mediaMode": "relayed", "archiveMode": "manual", "location": nil, "e2ee": false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.session.MarshalJSON()
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %v", err)
+			}
+			got := &Session{}
+			if err := got.UnmarshalJSON(data); err != nil {
+				t.Fatalf("UnmarshalJSON() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.session) {
+				t.Errorf("UnmarshalJSON() = %+v, want %+v", got, tt.session)
+			}
+			if _, err := got.GenerateToken(nil); err != ErrorSessionNotBound {
+				t.Errorf("GenerateToken() error = %v, want %v", err, ErrorSessionNotBound)
+			}
+		})
+	}
+}
+
+func TestRestoreSession(t *testing.T) {
+	type args struct {
+		sessionId  string
+		properties map[string]interface{}
+	}
+	tests := []struct {
+		name            string
+		apiKey          string
+		args            args
+		wantMediaMode   string
+		wantArchiveMode string
+		wantErr         error
+	}{
+		{
+			"restore",
+			"46513602",
+			args{"2_MX40NjUxMzYwMn5-MTU4NDgwNjg4MTI2MX55NG5zMzBaN1loUi9YVHVmV1pkRkNkRTV-UH4", map[string]interface{}{"mediaMode": "routed"}},
+			"routed",
+			"manual",
+			nil,
+		},
+		{
+			"wrong_api_key",
+			"1234567",
+			args{"2_MX40NjUxMzYwMn5-MTU4NDgwNjg4MTI2MX55NG5zMzBaN1loUi9YVHVmV1pkRkNkRTV-UH4", nil},
+			"",
+			"",
+			ErrorSessionApiKey,
+		},
+		{"empty_session_id", "46513602", args{"", nil}, "", "", ErrorNoSessionId},
+		{"invalid_session_id", "46513602", args{"2_", nil}, "", "", ErrorInvalidSession},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ot := NewOpenTok(tt.apiKey, "d06eaf53e214c105f02f2615170a04e08bf39aa6", nil)
+			got, err := RestoreSession(ot, tt.args.sessionId, tt.args.properties)
+			if err != tt.wantErr {
+				t.Fatalf("RestoreSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.MediaMode() != tt.wantMediaMode || got.ArchiveMode() != tt.wantArchiveMode {
+				t.Errorf("RestoreSession() = %v/%v, want %v/%v", got.MediaMode(), got.ArchiveMode(), tt.wantMediaMode, tt.wantArchiveMode)
+			}
+			if _, err := got.GenerateToken(nil); err != nil {
+				t.Errorf("GenerateToken() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestSession_Bind(t *testing.T) {
+	stored := &Session{
+		sessionId:  "2_MX40NjUxMzYwMn5-MTU4NDgwNjg4MTI2MX55NG5zMzBaN1loUi9YVHVmV1pkRkNkRTV-UH4",
+		properties: map[string]interface{}{"mediaMode": "routed", "archiveMode": "manual", "location": nil, "e2ee": false},
+		metadata:   &SessionMetadata{SessionId: "2_MX40", ProjectId: "46513602"},
+	}
+	data, err := stored.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	session := &Session{}
+	if err := session.UnmarshalJSON(data); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+
+	if err := session.Bind(nil); err != ErrorSessionNotBound {
+		t.Errorf("Bind() error = %v, want %v", err, ErrorSessionNotBound)
+	}
+	if err := session.Bind(NewOpenTok("1234567", "d06eaf53e214c105f02f2615170a04e08bf39aa6", nil)); err != ErrorSessionApiKey {
+		t.Errorf("Bind() error = %v, want %v", err, ErrorSessionApiKey)
+	}
+	if _, err := session.GenerateToken(nil); err != ErrorSessionNotBound {
+		t.Errorf("GenerateToken() error = %v, want %v after a failed Bind", err, ErrorSessionNotBound)
+	}
+
+	if err := session.Bind(NewOpenTok("46513602", "d06eaf53e214c105f02f2615170a04e08bf39aa6", nil)); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if !reflect.DeepEqual(session.Metadata(), stored.metadata) {
+		t.Errorf("Metadata() = %+v, want %+v", session.Metadata(), stored.metadata)
+	}
+	if _, err := session.GenerateToken(nil); err != nil {
+		t.Errorf("GenerateToken() error = %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"errors"
+	"net/http"
+)
+
+const (
+	// Maximum length of a signal type.
+	SignalTypeMaxLength = 128
+	// Maximum size, in bytes, of the data of a signal.
+	SignalDataMaxSize = 8192
+)
+
+var (
+	ErrorMissingConnectionId = errors.New("a connectionId parameter is required")
+	ErrorSignalType          = errors.New("invalid signal type, must be at most 128 characters of A-Z, a-z, 0-9, '-', '_' and '~'")
+	ErrorSignalDataSize      = errors.New("invalid signal data, must be at most 8192 bytes")
+)
+
+// A signal sent to the clients connected to an OpenTok session.
+type Signal struct {
+	// The type of the signal, clients can listen for signals of a given type. Optional.
+	Type string `json:"type,omitempty"`
+	// The data of the signal.
+	Data string `json:"data"`
+}
+
+func (s *Signal) validate() error {
+	if len(s.Type) > SignalTypeMaxLength {
+		return ErrorSignalType
+	}
+	for _, r := range s.Type {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '~') {
+			return ErrorSignalType
+		}
+	}
+	if len(s.Data) > SignalDataMaxSize {
+		return ErrorSignalDataSize
+	}
+	return nil
+}
+
+func (c *Client) sendSignal(sessionId string, signal *Signal) error {
+	return c.request(http.MethodPost, c.endpointUrl(c.config.Endpoints.Signal, sessionId), signal, nil,
+		map[int]error{
+			http.StatusNotFound:              ErrorSessionNotFound,
+			http.StatusRequestEntityTooLarge: ErrorSignalDataSize,
+		})
+}
+
+func (c *Client) sendSignalToConnection(sessionId, connectionId string, signal *Signal) error {
+	return c.request(http.MethodPost, c.endpointUrl(c.config.Endpoints.SignalConnection, sessionId, connectionId), signal, nil,
+		map[int]error{
+			http.StatusNotFound:              ErrorConnectionNotFound,
+			http.StatusRequestEntityTooLarge: ErrorSignalDataSize,
+		})
+}
+
+// Sends a signal to all the clients connected to an OpenTok session.
+//
+// @param sessionId The session ID of the OpenTok session.
+// @param signal The signal. The type is limited to 128 characters of A-Z, a-z, 0-9, '-', '_'
+// and '~', the data to 8192 bytes.
+func (ot *OpenTok) SendSignal(sessionId string, signal Signal) error {
+	if len(sessionId) == 0 {
+		return ErrorMissingSessionId
+	}
+	if err := signal.validate(); err != nil {
+		return err
+	}
+	return ot.client.sendSignal(sessionId, &signal)
+}
+
+// Sends a signal to one client connected to an OpenTok session.
+//
+// @param sessionId The session ID of the OpenTok session.
+// @param connectionId The connection ID of the client.
+// @param signal The signal, see {@link OpenTok#SendSignal OpenTok.SendSignal()}.
+//
+// @return The error wraps ErrorConnectionNotFound when the client is not connected to the
+// session.
+func (ot *OpenTok) SendSignalToConnection(sessionId, connectionId string, signal Signal) error {
+	if len(sessionId) == 0 {
+		return ErrorMissingSessionId
+	}
+	if len(connectionId) == 0 {
+		return ErrorMissingConnectionId
+	}
+	if err := signal.validate(); err != nil {
+		return err
+	}
+	return ot.client.sendSignalToConnection(sessionId, connectionId, &signal)
+}
+
+// Sends a signal to all the clients connected to this session.
+// See {@link OpenTok#SendSignal OpenTok.SendSignal()}.
+func (s *Session) SendSignal(signal Signal) error {
+	if s.ot == nil {
+		return ErrorSessionNotBound
+	}
+	return s.ot.SendSignal(s.sessionId, signal)
+}
+
+// Sends a signal to one client connected to this session.
+// See {@link OpenTok#SendSignalToConnection OpenTok.SendSignalToConnection()}.
+func (s *Session) SendSignalToConnection(connectionId string, signal Signal) error {
+	if s.ot == nil {
+		return ErrorSessionNotBound
+	}
+	return s.ot.SendSignalToConnection(s.sessionId, connectionId, signal)
+}
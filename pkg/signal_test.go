@@ -0,0 +1,63 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenTok_SendSignal(t *testing.T) {
+	var gotPath string
+	var gotSignal *Signal
+	ot := newTestOpenTok(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotSignal = r.URL.Path, nil
+		_ = json.NewDecoder(r.Body).Decode(&gotSignal)
+		switch r.URL.Path {
+		case "/v2/project/" + testApiKey + "/session/1_session/signal",
+			"/v2/project/" + testApiKey + "/session/1_session/connection/conn1/signal":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	tests := []struct {
+		name         string
+		sessionId    string
+		connectionId string
+		signal       Signal
+		wantPath     string
+		wantErr      error
+	}{
+		{"session", "1_session", "", Signal{"chat", "hello"}, "/v2/project/" + testApiKey + "/session/1_session/signal", nil},
+		{"connection", "1_session", "conn1", Signal{"call-ending_2~m", "2 minutes"}, "/v2/project/" + testApiKey + "/session/1_session/connection/conn1/signal", nil},
+		{"unknown_session", "1_other", "", Signal{"chat", "hello"}, "/v2/project/" + testApiKey + "/session/1_other/signal", ErrorSessionNotFound},
+		{"unknown_connection", "1_session", "conn2", Signal{"chat", "hello"}, "/v2/project/" + testApiKey + "/session/1_session/connection/conn2/signal", ErrorConnectionNotFound},
+		{"type_characters", "1_session", "", Signal{"chat:message", "hello"}, "", ErrorSignalType},
+		{"type_length", "1_session", "", Signal{strings.Repeat("a", 129), "hello"}, "", ErrorSignalType},
+		{"data_size", "1_session", "conn1", Signal{"chat", strings.Repeat("a", 8193)}, "", ErrorSignalDataSize},
+		{"no_session_id", "", "", Signal{"chat", "hello"}, "", ErrorMissingSessionId},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPath, gotSignal = "", nil
+			var err error
+			if len(tt.connectionId) == 0 {
+				err = ot.SendSignal(tt.sessionId, tt.signal)
+			} else {
+				err = ot.SendSignalToConnection(tt.sessionId, tt.connectionId, tt.signal)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SendSignal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("SendSignal() path = %v, want %v", gotPath, tt.wantPath)
+			}
+			if len(tt.wantPath) != 0 && !reflect.DeepEqual(gotSignal, &tt.signal) {
+				t.Errorf("SendSignal() signal = %+v, want %+v", gotSignal, tt.signal)
+			}
+		})
+	}
+}
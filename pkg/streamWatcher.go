@@ -0,0 +1,225 @@
+package pkg
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Lists the streams of a session, implemented by {@link OpenTok OpenTok}.
+type StreamLister interface {
+	ListStreams(sessionId string) ([]*StreamInfo, error)
+}
+
+// The settings of a StreamWatcher. The callbacks it emits are built from ListStreams, so their
+// streams only carry the ID, name and video type: Connection is nil and CreatedAt is 0.
+type StreamWatcherConfig struct {
+	// The project (API key) reported in the emitted callbacks.
+	ProjectId string
+	// How often each session is polled (default 5s).
+	Interval time.Duration
+	// Upper bound of the delay between polls of a session that keeps failing, the delay doubles
+	// on every consecutive failure (default 1m).
+	MaxBackoff time.Duration
+	// Called when polling a session fails. Optional.
+	OnError func(sessionId string, err error)
+}
+
+type watchedSession struct {
+	// streams seen in the last successful poll, nil until the first one
+	streams  map[string]*StreamInfo
+	failures int
+	next     time.Time
+}
+
+// Polls the streams of a set of sessions and emits streamCreated/streamDestroyed callbacks for
+// the differences between polls, for deployments that cannot receive OpenTok webhooks.
+type StreamWatcher struct {
+	lister   StreamLister
+	config   StreamWatcherConfig
+	mu       sync.Mutex
+	sessions map[string]*watchedSession
+}
+
+// Creates a StreamWatcher for the given sessions, more can be added with Add.
+//
+// @param lister Usually the {@link OpenTok OpenTok} instance.
+// @param config The polling interval, backoff and error callback.
+// @param sessionIds The sessions to watch.
+func NewStreamWatcher(lister StreamLister, config StreamWatcherConfig, sessionIds ...string) *StreamWatcher {
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = time.Minute
+	}
+	if config.MaxBackoff < config.Interval {
+		config.MaxBackoff = config.Interval
+	}
+	w := &StreamWatcher{
+		lister:   lister,
+		config:   config,
+		sessions: make(map[string]*watchedSession, len(sessionIds)),
+	}
+	for _, sessionId := range sessionIds {
+		w.Add(sessionId)
+	}
+	return w
+}
+
+// Starts watching a session. The streams found by its first poll are emitted as created.
+func (w *StreamWatcher) Add(sessionId string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.sessions[sessionId]; !ok {
+		w.sessions[sessionId] = &watchedSession{}
+	}
+}
+
+// Stops watching a session, no destroyed callbacks are emitted for its streams.
+func (w *StreamWatcher) Remove(sessionId string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.sessions, sessionId)
+}
+
+// Polls the watched sessions until ctx ends and emits a StreamCallback with the
+// EventStreamCreated or EventStreamDestroyed event for every stream that appeared or
+// disappeared. The returned channel is closed once ctx ends.
+//
+// Unlike the webhook callbacks, the emitted streams have a nil Connection and a CreatedAt of 0,
+// which ListStreams does not return. Consumers must not rely on them, e.g. to decode the
+// connection data.
+func (w *StreamWatcher) Watch(ctx context.Context) <-chan *StreamCallback {
+	events := make(chan *StreamCallback)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(w.config.Interval)
+		defer ticker.Stop()
+		for {
+			if !w.poll(ctx, events) {
+				return
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// polls the sessions that are due, returns false once ctx ended
+func (w *StreamWatcher) poll(ctx context.Context, events chan<- *StreamCallback) bool {
+	now := time.Now()
+	w.mu.Lock()
+	due := make([]string, 0, len(w.sessions))
+	for sessionId, session := range w.sessions {
+		if !now.Before(session.next) {
+			due = append(due, sessionId)
+		}
+	}
+	w.mu.Unlock()
+	sort.Strings(due)
+
+	for _, sessionId := range due {
+		if ctx.Err() != nil {
+			return false
+		}
+		streams, err := w.lister.ListStreams(sessionId)
+
+		w.mu.Lock()
+		session, ok := w.sessions[sessionId]
+		if !ok {
+			// removed while polling
+			w.mu.Unlock()
+			continue
+		}
+		if err != nil {
+			session.failures++
+			session.next = time.Now().Add(w.backoff(session.failures))
+			w.mu.Unlock()
+			if w.config.OnError != nil {
+				w.config.OnError(sessionId, err)
+			}
+			continue
+		}
+		session.failures = 0
+		session.next = time.Time{}
+		callbacks := w.diff(sessionId, session, streams)
+		w.mu.Unlock()
+
+		for _, callback := range callbacks {
+			select {
+			case events <- callback:
+			case <-ctx.Done():
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (w *StreamWatcher) backoff(failures int) time.Duration {
+	delay := w.config.Interval
+	for i := 0; i < failures && delay < w.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > w.config.MaxBackoff {
+		delay = w.config.MaxBackoff
+	}
+	return delay
+}
+
+// replaces the known streams of the session and returns the callbacks for the differences
+func (w *StreamWatcher) diff(sessionId string, session *watchedSession, streams []*StreamInfo) []*StreamCallback {
+	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
+	current := make(map[string]*StreamInfo, len(streams))
+	for _, stream := range streams {
+		if stream != nil {
+			current[stream.Id] = stream
+		}
+	}
+
+	var created, destroyed []string
+	for streamId := range current {
+		if _, ok := session.streams[streamId]; !ok {
+			created = append(created, streamId)
+		}
+	}
+	for streamId := range session.streams {
+		if _, ok := current[streamId]; !ok {
+			destroyed = append(destroyed, streamId)
+		}
+	}
+	sort.Strings(created)
+	sort.Strings(destroyed)
+
+	callbacks := make([]*StreamCallback, 0, len(created)+len(destroyed))
+	for _, streamId := range destroyed {
+		callbacks = append(callbacks, w.callback(sessionId, EventStreamDestroyed, session.streams[streamId], timestamp))
+	}
+	for _, streamId := range created {
+		callbacks = append(callbacks, w.callback(sessionId, EventStreamCreated, current[streamId], timestamp))
+	}
+	session.streams = current
+	return callbacks
+}
+
+func (w *StreamWatcher) callback(sessionId, event string, stream *StreamInfo, timestamp int64) *StreamCallback {
+	return &StreamCallback{
+		Callback: Callback{
+			SessionID: sessionId,
+			ProjectID: w.config.ProjectId,
+			Event:     event,
+			Timestamp: timestamp,
+		},
+		Stream: &Stream{
+			ID:        stream.Id,
+			Name:      stream.Name,
+			VideoType: stream.VideoType,
+		},
+	}
+}
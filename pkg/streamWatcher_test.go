@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeStreamLister struct {
+	mu      sync.Mutex
+	streams map[string][]*StreamInfo
+	err     error
+}
+
+func (f *fakeStreamLister) ListStreams(sessionId string) ([]*StreamInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.streams[sessionId], nil
+}
+
+func (f *fakeStreamLister) set(sessionId string, streams []*StreamInfo, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.streams[sessionId] = streams
+	f.err = err
+}
+
+func TestStreamWatcher_Watch(t *testing.T) {
+	lister := &fakeStreamLister{streams: map[string][]*StreamInfo{
+		"1_session": {{Id: "a", VideoType: VideoTypeCamera}},
+	}}
+	var errorCount int
+	var errorMu sync.Mutex
+	watcher := NewStreamWatcher(lister, StreamWatcherConfig{
+		ProjectId:  testApiKey,
+		Interval:   time.Millisecond,
+		MaxBackoff: 4 * time.Millisecond,
+		OnError: func(sessionId string, err error) {
+			errorMu.Lock()
+			errorCount++
+			errorMu.Unlock()
+		},
+	}, "1_session")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := watcher.Watch(ctx)
+
+	next := func() (string, string) {
+		select {
+		case event := <-events:
+			if event.SessionID != "1_session" || event.ProjectID != testApiKey {
+				t.Errorf("Watch() event = %+v", event.Callback)
+			}
+			return event.Event, event.Stream.ID
+		case <-time.After(2 * time.Second):
+			t.Fatal("Watch() no event before deadline")
+		}
+		return "", ""
+	}
+
+	if event, streamId := next(); event != EventStreamCreated || streamId != "a" {
+		t.Errorf("Watch() = %v %v, want %v a", event, streamId, EventStreamCreated)
+	}
+
+	// errors back off and keep the known streams
+	lister.set("1_session", nil, errors.New("unavailable"))
+	waitFor(t, func() bool {
+		errorMu.Lock()
+		defer errorMu.Unlock()
+		return errorCount >= 3
+	})
+
+	lister.set("1_session", []*StreamInfo{{Id: "b", VideoType: VideoTypeScreen}}, nil)
+	var got [][2]string
+	for i := 0; i < 2; i++ {
+		event, streamId := next()
+		got = append(got, [2]string{event, streamId})
+	}
+	want := [][2]string{{EventStreamDestroyed, "a"}, {EventStreamCreated, "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Watch() = %v, want %v", got, want)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestStreamWatcher_backoff(t *testing.T) {
+	watcher := NewStreamWatcher(nil, StreamWatcherConfig{Interval: time.Second, MaxBackoff: 10 * time.Second})
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{4, 10 * time.Second},
+		{50, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := watcher.backoff(tt.failures); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
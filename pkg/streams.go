@@ -0,0 +1,241 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrorNoStreamClassLists    = errors.New("at least one stream class list is required")
+	ErrorDuplicateStream       = errors.New("the stream is listed more than once")
+	ErrorInvalidLayoutClass    = errors.New("invalid layout class, class names cannot be empty or contain whitespace")
+	ErrorLayoutClassListLength = errors.New("invalid layout class list, must have concatenated length of less than 1024")
+)
+
+const (
+	VideoTypeCamera = "camera"
+	VideoTypeScreen = "screen"
+	VideoTypeCustom = "custom"
+)
+
+// Describes a stream published in an OpenTok session, as returned by the OpenTok REST API.
+type StreamInfo struct {
+	// The stream ID.
+	Id string `json:"id"`
+	// Either "camera", "screen" or "custom".
+	VideoType string `json:"videoType"`
+	// The stream name, if one was set when the stream was published.
+	Name string `json:"name"`
+	// The layout classes of the stream.
+	LayoutClassList []string `json:"layoutClassList"`
+}
+
+type StreamList struct {
+	Count int           `json:"count"`
+	Items []*StreamInfo `json:"items"`
+}
+
+// The layout classes to set on one stream, see
+// {@link OpenTok#SetStreamClassLists OpenTok.SetStreamClassLists()}.
+type StreamClassList struct {
+	// The stream ID.
+	Id string `json:"id"`
+	// The layout classes, an empty list clears the classes of the stream.
+	LayoutClassList []string `json:"layoutClassList"`
+}
+
+// The validation error of one stream of a SetStreamClassLists call.
+type StreamClassListError struct {
+	StreamId string
+	Err      error
+}
+
+func (e *StreamClassListError) Error() string {
+	return fmt.Sprintf("stream %s: %v", e.StreamId, e.Err)
+}
+
+func (e *StreamClassListError) Unwrap() error {
+	return e.Err
+}
+
+// Returned by SetStreamClassLists when one or more streams have an invalid class list.
+type StreamClassListErrors []*StreamClassListError
+
+func (e StreamClassListErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("invalid stream class lists: %s", strings.Join(messages, "; "))
+}
+
+func validateLayoutClassList(classList []string) error {
+	for _, class := range classList {
+		if len(class) == 0 || strings.IndexFunc(class, unicode.IsSpace) >= 0 {
+			return ErrorInvalidLayoutClass
+		}
+	}
+	if len(strings.Join(classList, " ")) > 1024 {
+		return ErrorLayoutClassListLength
+	}
+	return nil
+}
+
+func validateStreamClassLists(classLists []*StreamClassList) error {
+	if len(classLists) == 0 {
+		return ErrorNoStreamClassLists
+	}
+	var errs StreamClassListErrors
+	seen := make(map[string]bool, len(classLists))
+	for _, classList := range classLists {
+		if classList == nil {
+			continue
+		}
+		var err error
+		switch {
+		case len(classList.Id) == 0:
+			err = ErrorMissingStreamId
+		case seen[classList.Id]:
+			err = ErrorDuplicateStream
+		default:
+			err = validateLayoutClassList(classList.LayoutClassList)
+		}
+		seen[classList.Id] = true
+		if err != nil {
+			errs = append(errs, &StreamClassListError{StreamId: classList.Id, Err: err})
+		}
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+func (c *Client) getStream(sessionId, streamId string) (*StreamInfo, error) {
+	var stream StreamInfo
+	err := c.request(http.MethodGet, c.endpointUrl(c.config.Endpoints.GetStream, sessionId, streamId), nil, &stream,
+		map[int]error{
+			http.StatusBadRequest:     ErrorSessionNotFound,
+			http.StatusNotFound:       ErrorStreamNotFound,
+			http.StatusRequestTimeout: ErrorStreamNotFound,
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &stream, nil
+}
+
+func (c *Client) listStreams(sessionId string) (*StreamList, error) {
+	var streams StreamList
+	err := c.request(http.MethodGet, c.endpointUrl(c.config.Endpoints.ListStreams, sessionId), nil, &streams,
+		map[int]error{
+			http.StatusBadRequest: ErrorSessionNotFound,
+			http.StatusNotFound:   ErrorSessionNotFound,
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &streams, nil
+}
+
+func (c *Client) setStreamClassLists(sessionId string, classLists []*StreamClassList) error {
+	items := make([]*StreamClassList, 0, len(classLists))
+	for _, classList := range classLists {
+		if classList == nil {
+			continue
+		}
+		if classList.LayoutClassList == nil {
+			// an empty list clears the classes, null is rejected by the API
+			classList = &StreamClassList{Id: classList.Id, LayoutClassList: []string{}}
+		}
+		items = append(items, classList)
+	}
+	body := map[string]interface{}{"items": items}
+	return c.request(http.MethodPut, c.endpointUrl(c.config.Endpoints.SetStreamClassLists, sessionId), body, nil,
+		map[int]error{
+			http.StatusNotFound: ErrorSessionNotFound,
+		})
+}
+
+// Gets information about a stream published in an OpenTok session.
+//
+// @param sessionId The session ID of the OpenTok session containing the stream.
+// @param streamId The stream ID.
+//
+// @return The stream. The error wraps ErrorSessionNotFound or ErrorStreamNotFound when the
+// session or the stream does not exist.
+func (ot *OpenTok) GetStream(sessionId, streamId string) (*StreamInfo, error) {
+	if len(sessionId) == 0 {
+		return nil, ErrorMissingSessionId
+	}
+	if len(streamId) == 0 {
+		return nil, ErrorMissingStreamId
+	}
+	return ot.client.getStream(sessionId, streamId)
+}
+
+// Lists the streams published in an OpenTok session.
+//
+// @param sessionId The session ID of the OpenTok session.
+//
+// @return The streams. The error wraps ErrorSessionNotFound when the session does not exist.
+func (ot *OpenTok) ListStreams(sessionId string) ([]*StreamInfo, error) {
+	if len(sessionId) == 0 {
+		return nil, ErrorMissingSessionId
+	}
+	streams, err := ot.client.listStreams(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	return streams.Items, nil
+}
+
+// Sets the layout classes of streams published in an OpenTok session. Layout classes are used
+// to position streams in composed archives and live streaming broadcasts.
+//
+// @param sessionId The session ID of the OpenTok session containing the streams.
+// @param classLists The stream IDs and their new layout class lists. Class names cannot be
+// empty or contain whitespace and the concatenated class list of each stream must be shorter
+// than 1024 characters, as for the <code>initialLayoutClassList</code> token option.
+//
+// @return StreamClassListErrors listing every invalid stream, in which case no request is
+// sent, or the error of the REST call.
+func (ot *OpenTok) SetStreamClassLists(sessionId string, classLists []*StreamClassList) error {
+	if len(sessionId) == 0 {
+		return ErrorMissingSessionId
+	}
+	if err := validateStreamClassLists(classLists); err != nil {
+		return err
+	}
+	return ot.client.setStreamClassLists(sessionId, classLists)
+}
+
+// Gets information about a stream published in this session.
+// See {@link OpenTok#GetStream OpenTok.GetStream()}.
+func (s *Session) GetStream(streamId string) (*StreamInfo, error) {
+	if s.ot == nil {
+		return nil, ErrorSessionNotBound
+	}
+	return s.ot.GetStream(s.sessionId, streamId)
+}
+
+// Lists the streams published in this session.
+// See {@link OpenTok#ListStreams OpenTok.ListStreams()}.
+func (s *Session) ListStreams() ([]*StreamInfo, error) {
+	if s.ot == nil {
+		return nil, ErrorSessionNotBound
+	}
+	return s.ot.ListStreams(s.sessionId)
+}
+
+// Sets the layout classes of streams published in this session.
+// See {@link OpenTok#SetStreamClassLists OpenTok.SetStreamClassLists()}.
+func (s *Session) SetStreamClassLists(classLists []*StreamClassList) error {
+	if s.ot == nil {
+		return ErrorSessionNotBound
+	}
+	return s.ot.SetStreamClassLists(s.sessionId, classLists)
+}
@@ -0,0 +1,142 @@
+package pkg
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenTok_GetStream(t *testing.T) {
+	ot := newTestOpenTok(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/project/" + testApiKey + "/session/1_session/stream/stream1":
+			_, _ = w.Write([]byte(`{"id":"stream1","videoType":"screen","name":"slides","layoutClassList":["full"]}`))
+		case "/v2/project/" + testApiKey + "/session/1_session/stream/missing":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	tests := []struct {
+		name      string
+		sessionId string
+		streamId  string
+		want      *StreamInfo
+		wantErr   error
+	}{
+		{"found", "1_session", "stream1", &StreamInfo{"stream1", VideoTypeScreen, "slides", []string{"full"}}, nil},
+		{"unknown_stream", "1_session", "missing", nil, ErrorStreamNotFound},
+		{"unknown_session", "1_other", "stream1", nil, ErrorSessionNotFound},
+		{"no_session_id", "", "stream1", nil, ErrorMissingSessionId},
+		{"no_stream_id", "1_session", "", nil, ErrorMissingStreamId},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ot.GetStream(tt.sessionId, tt.streamId)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetStream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetStream() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenTok_ListStreams(t *testing.T) {
+	ot := newTestOpenTok(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/project/"+testApiKey+"/session/1_session/stream" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"count":2,"items":[{"id":"a","videoType":"camera"},{"id":"b","videoType":"custom","layoutClassList":[]}]}`))
+	})
+	tests := []struct {
+		name      string
+		sessionId string
+		want      []*StreamInfo
+		wantErr   error
+	}{
+		{"list", "1_session", []*StreamInfo{{Id: "a", VideoType: VideoTypeCamera}, {Id: "b", VideoType: VideoTypeCustom, LayoutClassList: []string{}}}, nil},
+		{"unknown_session", "1_other", nil, ErrorSessionNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ot.ListStreams(tt.sessionId)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ListStreams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListStreams() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenTok_SetStreamClassLists(t *testing.T) {
+	var requestBody string
+	ot := newTestOpenTok(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/v2/project/"+testApiKey+"/session/1_session/stream" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		requestBody = string(body)
+	})
+	tests := []struct {
+		name       string
+		sessionId  string
+		classLists []*StreamClassList
+		wantBody   string
+		wantErr    error
+		wantStream []string
+	}{
+		{
+			"set",
+			"1_session",
+			[]*StreamClassList{{"a", []string{"full", "focus"}}, {"b", nil}},
+			`{"items":[{"id":"a","layoutClassList":["full","focus"]},{"id":"b","layoutClassList":[]}]}`,
+			nil,
+			nil,
+		},
+		{"unknown_session", "1_other", []*StreamClassList{{"a", []string{"full"}}}, "", ErrorSessionNotFound, nil},
+		{"empty", "1_session", nil, "", ErrorNoStreamClassLists, nil},
+		{
+			"invalid_streams",
+			"1_session",
+			[]*StreamClassList{
+				{"a", []string{"full"}},
+				{"b", []string{"full focus"}},
+				{"", []string{"full"}},
+				{"a", []string{"focus"}},
+				{"c", []string{strings.Repeat("x", 1025)}},
+			},
+			"",
+			nil,
+			[]string{"b", "", "a", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestBody = ""
+			err := ot.SetStreamClassLists(tt.sessionId, tt.classLists)
+			if errs, ok := err.(StreamClassListErrors); ok || tt.wantStream != nil {
+				var streams []string
+				for _, e := range errs {
+					streams = append(streams, e.StreamId)
+				}
+				if !reflect.DeepEqual(streams, tt.wantStream) {
+					t.Errorf("SetStreamClassLists() invalid streams = %v, want %v", streams, tt.wantStream)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetStreamClassLists() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.TrimSpace(requestBody) != tt.wantBody {
+				t.Errorf("SetStreamClassLists() request body = %v, want %v", requestBody, tt.wantBody)
+			}
+		})
+	}
+}